@@ -0,0 +1,141 @@
+// Package auth checks the API keys of stream clients and counts their connections.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNoKey is returned for requests without a key
+	ErrNoKey = errors.New("an api key is required")
+
+	// ErrUnknownKey is returned for keys that are not configured
+	ErrUnknownKey = errors.New("unknown api key")
+
+	// ErrTooManyConnections is returned when a key is at its connection limit
+	ErrTooManyConnections = errors.New("too many connections for this api key")
+)
+
+// Key is the configuration of an API key
+type Key struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	// Channels the key may subscribe to, all of them when empty
+	Channels []string `json:"channels,omitempty"`
+
+	// Regions and locations (stations or structures) the key sees data
+	// of, all of them when empty
+	Regions   []int64 `json:"regions,omitempty"`
+	Locations []int64 `json:"locations,omitempty"`
+
+	// Concurrent connections allowed, unlimited when 0
+	MaxConnections int `json:"max_connections,omitempty"`
+}
+
+// Allows checks a channel is permitted
+func (k *Key) Allows(channel string) bool {
+	if len(k.Channels) == 0 {
+		return true
+	}
+	for _, c := range k.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Keyring holds the configured keys
+type Keyring struct {
+	// keys by the hash of their secret, so lookups do not compare secrets
+	keys map[[sha256.Size]byte]*Key
+
+	mutex       sync.Mutex
+	connections map[string]int
+}
+
+// NewKeyring reads a json array of Key
+func NewKeyring(path string) (*Keyring, error) {
+	raw, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	if err = json.Unmarshal(raw, &keys); err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{
+		keys:        make(map[[sha256.Size]byte]*Key),
+		connections: make(map[string]int),
+	}
+	names := make(map[string]bool)
+	for i := range keys {
+		key := keys[i]
+		if key.Name == "" || key.Key == "" {
+			return nil, errors.New("api keys need a name and a key")
+		}
+		if names[key.Name] {
+			return nil, fmt.Errorf("duplicate api key name %s", key.Name)
+		}
+		names[key.Name] = true
+		k.keys[sha256.Sum256([]byte(key.Key))] = &key
+	}
+	return k, nil
+}
+
+// Authorize finds the key of a request and counts a connection for it.
+// The returned release must be called when the connection ends.
+func (k *Keyring) Authorize(r *http.Request) (*Key, func(), error) {
+	return k.AuthorizeSecret(requestKey(r))
+}
+
+// AuthorizeSecret is Authorize for a key given outside of an http request,
+// such as gRPC metadata
+func (k *Keyring) AuthorizeSecret(secret string) (*Key, func(), error) {
+	if secret == "" {
+		return nil, nil, ErrNoKey
+	}
+	key, ok := k.keys[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return nil, nil, ErrUnknownKey
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if key.MaxConnections > 0 && k.connections[key.Name] >= key.MaxConnections {
+		return key, nil, ErrTooManyConnections
+	}
+	k.connections[key.Name]++
+
+	var once sync.Once
+	return key, func() {
+		once.Do(
+			func() {
+				k.mutex.Lock()
+				k.connections[key.Name]--
+				k.mutex.Unlock()
+			},
+		)
+	}, nil
+}
+
+// requestKey from the Authorization or X-API-Key header, or the key query
+// parameter for browsers, which can not set headers on websockets
+func requestKey(r *http.Request) string {
+	if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		return strings.TrimPrefix(bearer, "Bearer ")
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("key")
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	err := os.WriteFile(
+		path, []byte(`[{"name": "team", "key": "secret", "channels": ["market"], "max_connections": 1}]`), 0o600,
+	)
+	assert.Nil(t, err)
+	keys, err := NewKeyring(path)
+	assert.Nil(t, err)
+
+	_, _, err = keys.Authorize(httptest.NewRequest("GET", "/", nil))
+	assert.ErrorIs(t, err, ErrNoKey)
+	_, _, err = keys.Authorize(httptest.NewRequest("GET", "/?key=wrong", nil))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	key, release, err := keys.Authorize(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "team", key.Name)
+	assert.True(t, key.Allows("market"))
+	assert.False(t, key.Allows("contract"))
+
+	// One connection at a time
+	_, _, err = keys.Authorize(httptest.NewRequest("GET", "/?key=secret", nil))
+	assert.ErrorIs(t, err, ErrTooManyConnections)
+	release()
+	release()
+	_, _, err = keys.Authorize(httptest.NewRequest("GET", "/?key=secret", nil))
+	assert.Nil(t, err)
+}
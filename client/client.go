@@ -0,0 +1,440 @@
+// Package client follows the stream of an eve-marketwatch server and keeps
+// a mirror of its orders and contracts.
+//
+// The client reads the dump the server sends on connecting into the mirror,
+// then applies every broadcast to it before calling the handlers of its
+// action. When the connection drops it reconnects with a backoff, and the
+// next dump replaces what the mirror held of its regions.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/stream"
+	"github.com/gorilla/websocket"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// Reconnection delays, doubling from the first to the last
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+
+	// The connection is given up on after this long without a message. The
+	// status channel has a heartbeat every 30 seconds.
+	readTimeout = 90 * time.Second
+)
+
+// ErrRefused is returned by Run when the server turns the connection down
+// for its key, as for a key without one of the channels. Reconnecting would
+// not change its mind.
+var ErrRefused = errors.New("refused by the server")
+
+// Store each action applies to
+var channelOf = map[string]string{
+	"addition":         "market",
+	"change":           "market",
+	"deletion":         "market",
+	"contractAddition": "contract",
+	"contractChange":   "contract",
+	"contractDeletion": "contract",
+	"deal":             "contract",
+}
+
+// OrdersFunc handles orders added to a region in a cycle
+type OrdersFunc func(regionID int64, cycle uint64, orders []stream.Order)
+
+// OrderChangesFunc handles orders changed or deleted in a cycle
+type OrderChangesFunc func(regionID int64, cycle uint64, changes []stream.OrderChange)
+
+// ContractsFunc handles contracts added, or deals found, in a cycle
+type ContractsFunc func(regionID int64, cycle uint64, contracts []stream.FullContract)
+
+// ContractChangesFunc handles contracts changed or deleted in a cycle
+type ContractChangesFunc func(regionID int64, cycle uint64, changes []stream.ContractChange)
+
+// AlertsFunc handles alerts raised by the server's rules
+type AlertsFunc func(alerts []Alert)
+
+// StatusFunc handles cycles completed or failed on the server
+type StatusFunc func(status stream.CycleStatus)
+
+// SyncFunc is called once a dump is in the mirror, with its regions
+type SyncFunc func(regions []stream.SnapshotRegion)
+
+// Client follows the stream of a server
+type Client struct {
+	address  string
+	channels []string
+	key      string
+	log      *slog.Logger
+
+	mirror *Mirror
+
+	// Cycles the last dump held, broadcasts up to them are in it already
+	dumped map[storeKey]uint64
+
+	// The dump being read, nil outside of it
+	dump *dump
+
+	onOrderAddition    []OrdersFunc
+	onOrderChange      []OrderChangesFunc
+	onOrderDeletion    []OrderChangesFunc
+	onContractAddition []ContractsFunc
+	onContractChange   []ContractChangesFunc
+	onContractDeletion []ContractChangesFunc
+	onDeal             []ContractsFunc
+	onAlert            []AlertsFunc
+	onCycleComplete    []StatusFunc
+	onCycleFailed      []StatusFunc
+	onCycleDelayed     []StatusFunc
+	onSynced           []SyncFunc
+}
+
+// dump being read into the mirror
+type dump struct {
+	regions   []stream.SnapshotRegion
+	orders    map[int64]map[int64]stream.Order
+	contracts map[int64]map[int32]stream.FullContract
+}
+
+// New client of the server's websocket at address, e.g. ws://host:3005/,
+// for channels among market, contract, deals and alerts, market and contract
+// if none are given. The status channel is always followed, its heartbeats
+// keep quiet connections alive, so a key needs it too.
+func New(address string, channels ...string) *Client {
+	if len(channels) == 0 {
+		channels = []string{"market", "contract"}
+	}
+	return &Client{
+		address:  address,
+		channels: append(channels[:len(channels):len(channels)], "status"),
+		log:      slog.Default(),
+		mirror:   newMirror(),
+		dumped:   make(map[storeKey]uint64),
+	}
+}
+
+// UseKey authenticates with an API key
+func (c *Client) UseKey(key string) {
+	c.key = key
+}
+
+// SetLogger logs to l instead of the default logger
+func (c *Client) SetLogger(l *slog.Logger) {
+	c.log = l
+}
+
+// Mirror of the server's orders and contracts
+func (c *Client) Mirror() *Mirror {
+	return c.mirror
+}
+
+// OnOrderAddition calls f with new orders. Handlers run on the goroutine of
+// Run, after the mirror is updated, and hold up the stream until they return.
+func (c *Client) OnOrderAddition(f OrdersFunc) {
+	c.onOrderAddition = append(c.onOrderAddition, f)
+}
+
+// OnOrderChange calls f with changed orders
+func (c *Client) OnOrderChange(f OrderChangesFunc) {
+	c.onOrderChange = append(c.onOrderChange, f)
+}
+
+// OnOrderDeletion calls f with orders that left the market
+func (c *Client) OnOrderDeletion(f OrderChangesFunc) {
+	c.onOrderDeletion = append(c.onOrderDeletion, f)
+}
+
+// OnContractAddition calls f with new contracts
+func (c *Client) OnContractAddition(f ContractsFunc) {
+	c.onContractAddition = append(c.onContractAddition, f)
+}
+
+// OnContractChange calls f with contracts that got bids
+func (c *Client) OnContractChange(f ContractChangesFunc) {
+	c.onContractChange = append(c.onContractChange, f)
+}
+
+// OnContractDeletion calls f with contracts that left the public list
+func (c *Client) OnContractDeletion(f ContractChangesFunc) {
+	c.onContractDeletion = append(c.onContractDeletion, f)
+}
+
+// OnDeal calls f with new contracts priced under their value, which needs
+// the deals channel
+func (c *Client) OnDeal(f ContractsFunc) {
+	c.onDeal = append(c.onDeal, f)
+}
+
+// OnAlert calls f with alerts, which needs the alerts channel
+func (c *Client) OnAlert(f AlertsFunc) {
+	c.onAlert = append(c.onAlert, f)
+}
+
+// OnCycleComplete calls f when a region's cycle is done on the server
+func (c *Client) OnCycleComplete(f StatusFunc) {
+	c.onCycleComplete = append(c.onCycleComplete, f)
+}
+
+// OnCycleFailed calls f when a region's cycle failed on the server
+func (c *Client) OnCycleFailed(f StatusFunc) {
+	c.onCycleFailed = append(c.onCycleFailed, f)
+}
+
+// OnCycleDelayed calls f when a region's cycle waits for the cache window
+// to turn on the server
+func (c *Client) OnCycleDelayed(f StatusFunc) {
+	c.onCycleDelayed = append(c.onCycleDelayed, f)
+}
+
+// OnSynced calls f once a dump is in the mirror. The dump does not go
+// through the other handlers, read the mirror instead.
+func (c *Client) OnSynced(f SyncFunc) {
+	c.onSynced = append(c.onSynced, f)
+}
+
+// Run follows the stream until ctx is done, reconnecting when the
+// connection drops. It gives up with ErrRefused when the key is not let in.
+func (c *Client) Run(ctx context.Context) error {
+	backoff := minBackoff
+	for {
+		synced, err := c.follow(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ErrRefused) {
+			return err
+		}
+		if synced {
+			backoff = minBackoff
+		}
+		c.log.Warn("stream lost, reconnecting", "retry_in", backoff.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// follow the stream until the connection fails, reporting if a dump was
+// read in full
+func (c *Client) follow(ctx context.Context) (bool, error) {
+	address, err := c.url()
+	if err != nil {
+		return false, err
+	}
+	header := http.Header{}
+	if c.key != "" {
+		header.Set("Authorization", "Bearer "+c.key)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  30 * time.Second,
+		EnableCompression: true,
+	}
+	conn, res, err := dialer.DialContext(ctx, address, header)
+	if err != nil {
+		if res != nil && (res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden) {
+			return false, fmt.Errorf("%w: %s", ErrRefused, res.Status)
+		}
+		if res != nil {
+			return false, fmt.Errorf("%w: %s", err, res.Status)
+		}
+		return false, err
+	}
+	defer conn.Close() //nolint:errcheck
+
+	// Unblock the read when we are stopped
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close() //nolint:errcheck,gosec
+		case <-done:
+		}
+	}()
+
+	c.dump = nil
+	synced := false
+	for {
+		err = conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if err != nil {
+			return synced, err
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return synced, err
+		}
+		m, err := Decode(data)
+		if err != nil {
+			return synced, err
+		}
+		if m.Action == "snapshotEnd" {
+			synced = true
+		}
+		c.handle(m)
+	}
+}
+
+// url subscribing to the channels
+func (c *Client) url() (string, error) {
+	u, err := url.Parse(c.address)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, channel := range c.channels {
+		q.Set(channel, "1")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// handle a message of the stream
+func (c *Client) handle(m stream.Message) {
+	switch p := m.Payload.(type) {
+	case []stream.SnapshotRegion:
+		if m.Action == "snapshotBegin" {
+			c.beginDump(p)
+		} else {
+			c.endDump()
+		}
+		return
+	case stream.CycleStatus:
+		handlers := c.onCycleComplete
+		switch m.Action {
+		case "cycleFailed":
+			handlers = c.onCycleFailed
+		case "cycleDelayed":
+			handlers = c.onCycleDelayed
+		}
+		for _, f := range handlers {
+			f(p)
+		}
+		return
+	case []Alert:
+		for _, f := range c.onAlert {
+			f(p)
+		}
+		return
+	}
+
+	channel, ok := channelOf[m.Action]
+	if !ok {
+		return
+	}
+	if c.dump != nil {
+		c.dump.add(m)
+		return
+	}
+	// The dump already holds this cycle
+	if m.Cycle <= c.dumped[storeKey{m.RegionID, channel}] {
+		return
+	}
+
+	c.mirror.apply(m)
+	c.dispatch(m)
+}
+
+// dispatch a broadcast to the handlers of its action
+func (c *Client) dispatch(m stream.Message) {
+	switch p := m.Payload.(type) {
+	case []stream.Order:
+		for _, f := range c.onOrderAddition {
+			f(m.RegionID, m.Cycle, p)
+		}
+	case []stream.OrderChange:
+		handlers := c.onOrderChange
+		if m.Action == "deletion" {
+			handlers = c.onOrderDeletion
+		}
+		for _, f := range handlers {
+			f(m.RegionID, m.Cycle, p)
+		}
+	case []stream.FullContract:
+		handlers := c.onContractAddition
+		if m.Action == "deal" {
+			handlers = c.onDeal
+		}
+		for _, f := range handlers {
+			f(m.RegionID, m.Cycle, p)
+		}
+	case []stream.ContractChange:
+		handlers := c.onContractChange
+		if m.Action == "contractDeletion" {
+			handlers = c.onContractDeletion
+		}
+		for _, f := range handlers {
+			f(m.RegionID, m.Cycle, p)
+		}
+	}
+}
+
+// beginDump starts reading a dump
+func (c *Client) beginDump(regions []stream.SnapshotRegion) {
+	c.dump = &dump{
+		regions:   regions,
+		orders:    make(map[int64]map[int64]stream.Order),
+		contracts: make(map[int64]map[int32]stream.FullContract),
+	}
+	for _, r := range regions {
+		switch r.Channel {
+		case "market":
+			c.dump.orders[r.RegionID] = make(map[int64]stream.Order, r.Count)
+		case "contract":
+			c.dump.contracts[r.RegionID] = make(map[int32]stream.FullContract, r.Count)
+		}
+	}
+}
+
+// endDump puts the dump in the mirror
+func (c *Client) endDump() {
+	d := c.dump
+	c.dump = nil
+	if d == nil {
+		return
+	}
+
+	c.mirror.replace(d)
+	for _, r := range d.regions {
+		c.dumped[storeKey{r.RegionID, r.Channel}] = r.Cycle
+	}
+	c.log.Info("synced", "regions", len(d.regions))
+	for _, f := range c.onSynced {
+		f(d.regions)
+	}
+}
+
+// add a message of the dump
+func (d *dump) add(m stream.Message) {
+	switch p := m.Payload.(type) {
+	case []stream.Order:
+		orders, ok := d.orders[m.RegionID]
+		if !ok {
+			return
+		}
+		for _, o := range p {
+			orders[o.OrderId] = o
+		}
+	case []stream.FullContract:
+		contracts, ok := d.contracts[m.RegionID]
+		if !ok {
+			return
+		}
+		for _, c := range p {
+			contracts[c.Contract.ContractId] = c
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/stream"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	dump := []stream.SnapshotRegion{{RegionID: 1, Channel: "market", Cycle: 3, Count: 2}}
+	messages := []stream.Message{
+		{Action: "snapshotBegin", Payload: dump},
+		{Action: "addition", RegionID: 1, Cycle: 3, Payload: []stream.Order{{OrderId: 1, Price: 10}, {OrderId: 2, Price: 20}}},
+		{Action: "snapshotEnd", Payload: dump},
+
+		// Already in the dump
+		{Action: "addition", RegionID: 1, Cycle: 3, Payload: []stream.Order{{OrderId: 9}}},
+
+		{Action: "addition", RegionID: 1, Cycle: 4, Payload: []stream.Order{{OrderId: 3, Price: 30}}},
+		{Action: "change", RegionID: 1, Cycle: 4, Payload: []stream.OrderChange{{OrderID: 1, Price: 11, VolumeRemain: 5}}},
+		{Action: "deletion", RegionID: 1, Cycle: 4, Payload: []stream.OrderChange{{OrderID: 2}}},
+		{Action: "cycleDelayed", Payload: stream.CycleStatus{RegionID: 1, Channel: "contract"}},
+		{Action: "cycleComplete", Payload: stream.CycleStatus{RegionID: 1, Channel: "market", Cycle: 4}},
+	}
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck
+		for _, m := range messages {
+			if conn.WriteJSON(m) != nil {
+				return
+			}
+		}
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	c := New("ws" + strings.TrimPrefix(server.URL, "http"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var synced []stream.SnapshotRegion
+	var added []int64
+	var changed, deleted []stream.OrderChange
+	var delayed []stream.CycleStatus
+	c.OnSynced(func(regions []stream.SnapshotRegion) { synced = regions })
+	c.OnOrderAddition(func(regionID int64, cycle uint64, orders []stream.Order) {
+		for _, o := range orders {
+			added = append(added, o.OrderId)
+		}
+	})
+	c.OnOrderChange(func(regionID int64, cycle uint64, changes []stream.OrderChange) { changed = changes })
+	c.OnOrderDeletion(func(regionID int64, cycle uint64, changes []stream.OrderChange) { deleted = changes })
+	c.OnCycleDelayed(func(status stream.CycleStatus) { delayed = append(delayed, status) })
+	c.OnCycleComplete(func(status stream.CycleStatus) { cancel() })
+
+	assert.ErrorIs(t, c.Run(ctx), context.Canceled)
+	assert.Equal(t, "contract=1&market=1&status=1", query)
+
+	assert.Equal(t, dump, synced)
+	assert.Equal(t, []int64{3}, added)
+	assert.Len(t, changed, 1)
+	assert.Len(t, deleted, 1)
+	assert.Equal(t, []stream.CycleStatus{{RegionID: 1, Channel: "contract"}}, delayed)
+
+	mirror := c.Mirror()
+	assert.Equal(t, []int64{1}, mirror.Regions())
+	assert.Equal(t, uint64(4), mirror.Cycle(1, "market"))
+	assert.Len(t, mirror.Orders(1), 2)
+
+	o, ok := mirror.Order(1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 11.0, o.Price)
+	assert.Equal(t, int32(5), o.VolumeRemain)
+
+	_, ok = mirror.Order(1, 2)
+	assert.False(t, ok)
+	_, ok = mirror.Order(1, 9)
+	assert.False(t, ok)
+}
+
+func TestRunRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	c := New("ws"+strings.TrimPrefix(server.URL, "http"), "deals")
+	c.UseKey("no-status")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Given up on at once, not retried
+	err := c.Run(ctx)
+	assert.ErrorIs(t, err, ErrRefused)
+	assert.ErrorContains(t, err, "403")
+	assert.Nil(t, ctx.Err())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode([]byte(`{"action": `))
+	assert.ErrorContains(t, err, "decoding a frame: ")
+
+	m, err := Decode([]byte(`{"action": "addition", "payload": {"order_id": 1}}`))
+	assert.ErrorContains(t, err, "decoding the payload of addition: ")
+	assert.Equal(t, "addition", m.Action)
+}
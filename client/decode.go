@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/stream"
+)
+
+// Alert raised by a rule of the server. The payload is the order, order
+// change, contract or contract change that matched, as the rule's kind and
+// action tell.
+type Alert struct {
+	RuleID   string          `json:"rule_id"`
+	RuleName string          `json:"rule_name,omitempty"`
+	Action   string          `json:"action"`
+	RegionID int64           `json:"region_id"`
+	Time     time.Time       `json:"time"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// frame is a message as it is read
+type frame struct {
+	Action   string          `json:"action"`
+	Payload  json.RawMessage `json:"payload"`
+	RegionID int64           `json:"region_id"`
+	Cycle    uint64          `json:"cycle"`
+}
+
+// Decode a json frame of the stream, with its payload typed by the action:
+//
+//	addition                           []stream.Order
+//	change, deletion                   []stream.OrderChange
+//	contractAddition, deal             []stream.FullContract
+//	contractChange, contractDeletion   []stream.ContractChange
+//	snapshotBegin, snapshotEnd         []stream.SnapshotRegion
+//	cycleComplete, cycleFailed,
+//	cycleDelayed                       stream.CycleStatus
+//	heartbeat                          stream.Heartbeat
+//	alert                              []Alert
+//
+// Payloads of other actions are left as json.RawMessage.
+func Decode(data []byte) (stream.Message, error) {
+	var f frame
+	if err := json.Unmarshal(data, &f); err != nil {
+		return stream.Message{}, fmt.Errorf("decoding a frame: %w", err)
+	}
+
+	m := stream.Message{Action: f.Action, RegionID: f.RegionID, Cycle: f.Cycle}
+	var err error
+	switch f.Action {
+	case "addition":
+		m.Payload, err = decodePayload[[]stream.Order](f.Payload)
+	case "change", "deletion":
+		m.Payload, err = decodePayload[[]stream.OrderChange](f.Payload)
+	case "contractAddition", "deal":
+		m.Payload, err = decodePayload[[]stream.FullContract](f.Payload)
+	case "contractChange", "contractDeletion":
+		m.Payload, err = decodePayload[[]stream.ContractChange](f.Payload)
+	case "snapshotBegin", "snapshotEnd":
+		m.Payload, err = decodePayload[[]stream.SnapshotRegion](f.Payload)
+	case "cycleComplete", "cycleFailed", "cycleDelayed":
+		m.Payload, err = decodePayload[stream.CycleStatus](f.Payload)
+	case "heartbeat":
+		m.Payload, err = decodePayload[stream.Heartbeat](f.Payload)
+	case "alert":
+		m.Payload, err = decodePayload[[]Alert](f.Payload)
+	default:
+		m.Payload = f.Payload
+	}
+	if err != nil {
+		return m, fmt.Errorf("decoding the payload of %s: %w", f.Action, err)
+	}
+	return m, nil
+}
+
+func decodePayload[T any](raw json.RawMessage) (interface{}, error) {
+	var payload T
+	err := json.Unmarshal(raw, &payload)
+	return payload, err
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/contorno/eve-marketwatch/stream"
+)
+
+// storeKey of a region's market or contracts
+type storeKey struct {
+	regionID int64
+	channel  string
+}
+
+// Mirror is a local copy of the server's orders and contracts, safe to
+// read while the client updates it
+type Mirror struct {
+	mutex     sync.RWMutex
+	orders    map[int64]map[int64]stream.Order        // region -> order ID
+	contracts map[int64]map[int32]stream.FullContract // region -> contract ID
+	cycles    map[storeKey]uint64
+}
+
+func newMirror() *Mirror {
+	return &Mirror{
+		orders:    make(map[int64]map[int64]stream.Order),
+		contracts: make(map[int64]map[int32]stream.FullContract),
+		cycles:    make(map[storeKey]uint64),
+	}
+}
+
+// Regions the mirror holds orders or contracts of
+func (m *Mirror) Regions() []int64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	seen := make(map[int64]bool)
+	var regions []int64
+	for k := range m.cycles {
+		if !seen[k.regionID] {
+			seen[k.regionID] = true
+			regions = append(regions, k.regionID)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+	return regions
+}
+
+// Cycle of a region's market or contracts the mirror is up to
+func (m *Mirror) Cycle(regionID int64, channel string) uint64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.cycles[storeKey{regionID, channel}]
+}
+
+// Orders of a region
+func (m *Mirror) Orders(regionID int64) []stream.Order {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	orders := make([]stream.Order, 0, len(m.orders[regionID]))
+	for _, o := range m.orders[regionID] {
+		orders = append(orders, o)
+	}
+	return orders
+}
+
+// Order of a region by ID
+func (m *Mirror) Order(regionID, orderID int64) (stream.Order, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	o, ok := m.orders[regionID][orderID]
+	return o, ok
+}
+
+// Contracts of a region
+func (m *Mirror) Contracts(regionID int64) []stream.FullContract {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	contracts := make([]stream.FullContract, 0, len(m.contracts[regionID]))
+	for _, c := range m.contracts[regionID] {
+		contracts = append(contracts, c)
+	}
+	return contracts
+}
+
+// Contract of a region by ID
+func (m *Mirror) Contract(regionID int64, contractID int32) (stream.FullContract, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	c, ok := m.contracts[regionID][contractID]
+	return c, ok
+}
+
+// replace the regions of a dump with what it held
+func (m *Mirror) replace(d *dump) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, r := range d.regions {
+		switch r.Channel {
+		case "market":
+			m.orders[r.RegionID] = d.orders[r.RegionID]
+		case "contract":
+			m.contracts[r.RegionID] = d.contracts[r.RegionID]
+		}
+		m.cycles[storeKey{r.RegionID, r.Channel}] = r.Cycle
+	}
+}
+
+// apply a broadcast
+func (m *Mirror) apply(msg stream.Message) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !stream.Apply((*mirrorStore)(m), msg) {
+		return
+	}
+	key := storeKey{msg.RegionID, channelOf[msg.Action]}
+	if msg.Cycle > m.cycles[key] {
+		m.cycles[key] = msg.Cycle
+	}
+}
+
+// mirrorStore applies the stream to a mirror, with its lock held
+type mirrorStore Mirror
+
+func (m *mirrorStore) PutOrder(regionID int64, o stream.Order) {
+	orders, ok := m.orders[regionID]
+	if !ok {
+		orders = make(map[int64]stream.Order)
+		m.orders[regionID] = orders
+	}
+	orders[o.OrderId] = o
+}
+
+func (m *mirrorStore) GetOrder(regionID, orderID int64) (stream.Order, bool) {
+	o, ok := m.orders[regionID][orderID]
+	return o, ok
+}
+
+func (m *mirrorStore) DeleteOrder(regionID, orderID int64) {
+	delete(m.orders[regionID], orderID)
+}
+
+func (m *mirrorStore) PutContract(regionID int64, c stream.FullContract) {
+	contracts, ok := m.contracts[regionID]
+	if !ok {
+		contracts = make(map[int32]stream.FullContract)
+		m.contracts[regionID] = contracts
+	}
+	contracts[c.Contract.ContractId] = c
+}
+
+func (m *mirrorStore) GetContract(regionID int64, contractID int32) (stream.FullContract, bool) {
+	c, ok := m.contracts[regionID][contractID]
+	return c, ok
+}
+
+func (m *mirrorStore) DeleteContract(regionID int64, contractID int32) {
+	delete(m.contracts[regionID], contractID)
+}
@@ -1,28 +1,39 @@
 package main
 
 import (
-	"log"
-	"net/http"
-	_ "net/http/pprof" //nolint:gosec
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
 	"github.com/contorno/eve-marketwatch/marketwatch"
 	"github.com/getsentry/sentry-go"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const version = "eve-marketwatch@0.0.4"
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.SetPrefix("eve-marketwatch: ")
-	log.Println("starting eve-marketwatch")
+	err := logging.Setup(os.Stderr, logLevels())
+	if err != nil {
+		logging.For("main").Error("bad LOG_LEVEL", logging.Error, err)
+		os.Exit(1)
+	}
+	logger := logging.For("main")
+
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		err := replay(os.Args[2:])
+		if err != nil {
+			logger.Error("replay failed", logging.Error, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger.Info("starting eve-marketwatch", "version", version)
 	dsn := os.Getenv("SENTRY_DSN")
 
-	err := sentry.Init(
+	err = sentry.Init(
 		sentry.ClientOptions{
 			Release:          version,
 			AttachStacktrace: true,
@@ -36,14 +47,15 @@ func main() {
 		},
 	)
 	if err != nil {
-		sentry.CaptureException(err)
-		log.Fatalf("sentry.Init: %s", err)
+		logger.Error("sentry.Init", logging.Error, err)
+		os.Exit(1)
 	}
 
 	mw, err := marketwatch.NewMarketWatch()
 	if err != nil {
-		sentry.CaptureException(err)
-		log.Fatalln(err)
+		logger.Error("failed to create the market watch", logging.Error, err)
+		sentry.Flush(3 * time.Second)
+		os.Exit(1)
 	}
 
 	go func(localHub *sentry.Hub) {
@@ -55,29 +67,38 @@ func main() {
 		err := mw.Run()
 
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Fatalln("failed to run market watch server")
+			logging.WithHub(logger, localHub).Error("failed to run market watch server", logging.Error, err)
+			sentry.Flush(3 * time.Second)
+			os.Exit(1)
 		}
-		log.Println("started the market watch server on port 3005")
 	}(sentry.CurrentHub().Clone())
 
-	http.Handle("/metrics", promhttp.Handler())
-	go func(localHub *sentry.Hub) {
-		localHub.ConfigureScope(
-			func(scope *sentry.Scope) {
-				scope.SetTag("locationHash", "go#serve-prometheus-metrics")
-			},
-		)
+	// Metrics and debugging stay off the public port
+	go serveMetrics(envOr("METRICS_ADDR", ":3000"), sentry.CurrentHub().Clone())
+	if addr := envOr("ADMIN_ADDR", "127.0.0.1:6060"); addr != "off" {
+		go serveAdmin(addr, os.Getenv("ADMIN_TOKEN"), mw, sentry.CurrentHub().Clone())
+	}
 
-		err := http.ListenAndServe(":3000", nil) //nolint:gosec
-		if err != nil {
-			sentry.CaptureException(err)
-			log.Fatalln("failed to run metrics server")
-		}
-		log.Println("started the metrics server on port 3000")
-	}(sentry.CurrentHub().Clone())
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	log.Println(<-ch)
+	logger.Info("stopping", "signal", (<-ch).String())
 	defer sentry.Flush(3 * time.Second)
 }
+
+// logLevels from LOG_LEVEL, or DEBUG=true for the esi request logs it used
+// to turn on
+func logLevels() string {
+	spec := os.Getenv("LOG_LEVEL")
+	if spec == "" && os.Getenv("DEBUG") == "true" {
+		spec = "info,esi=debug"
+	}
+	return spec
+}
+
+// envOr reads an environment variable with a default
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/client"
+	"github.com/contorno/eve-marketwatch/journal"
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/marketwatch"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/getsentry/sentry-go"
+)
+
+// replay streams a recorded journal through a websocket hub
+func replay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	dir := flags.String("journal", "journal", "journal directory to replay")
+	addr := flags.String("addr", ":3005", "address to serve the websocket on")
+	speed := flags.Float64("speed", 1, "replay speed, 1 is real time, 0 is as fast as possible")
+	from := flags.String("from", "", "start at this time (RFC 3339), defaults to the start of the journal")
+	wait := flags.Bool("wait", true, "wait for the first client before replaying")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *speed < 0 {
+		return errors.New("speed can not be negative")
+	}
+
+	var start time.Time
+	if *from != "" {
+		var err error
+		start, err = time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return err
+		}
+	}
+
+	reader, err := journal.NewReader(*dir, start)
+	if err != nil {
+		return err
+	}
+	defer reader.Close() //nolint:errcheck
+
+	logger := logging.For("replay")
+	hub := wsbroadcast.NewHub(marketwatch.Channels)
+	hub.SetLogger(logging.For("wsbroadcast"))
+	connected := make(chan bool, 1)
+	hub.OnRegister(
+		func(map[string]bool, chan interface{}) {
+			select {
+			case connected <- true:
+			default:
+			}
+		},
+	)
+	go hub.Run(sentry.CurrentHub().Clone())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/", func(w http.ResponseWriter, r *http.Request) {
+			err := hub.ServeWs(w, r)
+			if err != nil {
+				logger.Error("serving a websocket", logging.ClientAddr, r.RemoteAddr, logging.Error, err)
+			}
+		},
+	)
+	go func() {
+		err := http.ListenAndServe(*addr, mux) //nolint:gosec
+		logger.Error("serving the replay", logging.Error, err)
+		os.Exit(1)
+	}()
+	logger.Info("replaying", "journal", *dir, "addr", *addr, "speed", *speed)
+
+	if *wait {
+		logger.Info("waiting for a client")
+		<-connected
+	}
+
+	var previous time.Time
+	count := 0
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Keep the gaps between broadcasts, scaled by the speed
+		if *speed > 0 && !previous.IsZero() {
+			time.Sleep(time.Duration(float64(entry.Time.Sub(previous)) / *speed))
+		}
+		previous = entry.Time
+
+		m, err := decodeEntry(entry.Message)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", entry.Seq, err)
+		}
+		hub.Broadcast(entry.Channel, m)
+		count++
+	}
+
+	// Keep serving so clients can finish reading
+	logger.Info("replayed, interrupt to stop", "messages", count)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	logger.Info("stopping", "signal", (<-ch).String())
+	return nil
+}
+
+// decodeEntry types a recorded message the way it was when it was broadcast,
+// so clients get it the same in every encoding. Payloads the client package
+// does not type are decoded generically.
+func decodeEntry(raw json.RawMessage) (interface{}, error) {
+	m, err := client.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch m.Payload.(type) {
+	case json.RawMessage, []client.Alert:
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		var generic interface{}
+		if err = d.Decode(&generic); err != nil {
+			return nil, err
+		}
+		return withNumbers(generic), nil
+	}
+	return m, nil
+}
+
+// withNumbers turns the json numbers of a generic value into integers where
+// they are whole, and floats otherwise
+func withNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = withNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = withNumbers(e)
+		}
+	}
+	return v
+}
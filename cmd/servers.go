@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/marketwatch"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// serveMetrics serves prometheus metrics, and nothing else, on addr
+func serveMetrics(addr string, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#serve-prometheus-metrics")
+		},
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	logger := logging.WithHub(logging.For("main"), localHub)
+	logger.Info("serving metrics", "addr", addr)
+	err := http.ListenAndServe(addr, mux) //nolint:gosec
+	if err != nil {
+		logger.Error("failed to run metrics server", logging.Error, err)
+		os.Exit(1)
+	}
+}
+
+// serveAdmin serves pprof and the alert rules on addr, behind a bearer token
+// when one is set
+func serveAdmin(addr, token string, mw *marketwatch.MarketWatch, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#serve-admin")
+		},
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/rules", mw.ServeRules)
+
+	var handler http.Handler = mux
+	if token != "" {
+		handler = requireToken(token, mux)
+	}
+
+	logger := logging.WithHub(logging.For("main"), localHub)
+	logger.Info("serving admin", "addr", addr)
+	err := http.ListenAndServe(addr, handler) //nolint:gosec
+	if err != nil {
+		logger.Error("failed to run admin server", logging.Error, err)
+		os.Exit(1)
+	}
+}
+
+// requireToken refuses requests without the bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
@@ -0,0 +1,102 @@
+// Package journal records broadcasts to hourly, gzip compressed NDJSON files.
+//
+// Every entry is written as its own gzip member, so a file is a valid gzip
+// stream that can also be read from the offset of any entry. Next to each
+// file an index lists the sequence number, time and offset of its entries.
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Layout of the hour in file names
+const hourLayout = "2006-01-02T15"
+
+const (
+	dataSuffix  = ".ndjson.gz"
+	indexSuffix = ".idx"
+)
+
+// Entry is a recorded broadcast
+type Entry struct {
+	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Channel string          `json:"channel"`
+	Message json.RawMessage `json:"message"`
+}
+
+// IndexEntry locates an entry in its file
+type IndexEntry struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	Offset  int64     `json:"offset"`
+}
+
+// File is an hour of the journal
+type File struct {
+	Hour time.Time
+	Path string
+}
+
+// IndexPath of the file
+func (f File) IndexPath() string {
+	return strings.TrimSuffix(f.Path, dataSuffix) + indexSuffix
+}
+
+// Index reads the index of the file
+func (f File) Index() ([]IndexEntry, error) {
+	raw, err := os.ReadFile(f.IndexPath())
+	if err != nil {
+		return nil, err
+	}
+	var index []IndexEntry
+	for _, line := range strings.Split(string(raw), "\n") {
+		if line == "" {
+			continue
+		}
+		var e IndexEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			// A crash can leave half a line at the end
+			break
+		}
+		index = append(index, e)
+	}
+	return index, nil
+}
+
+// Files lists the journal files in a directory, oldest first
+func Files(dir string) ([]File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+dataSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for _, p := range paths {
+		hour, err := time.Parse(hourLayout, strings.TrimSuffix(filepath.Base(p), dataSuffix))
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Hour: hour, Path: p})
+	}
+	sort.Slice(
+		files, func(i, j int) bool {
+			return files[i].Hour.Before(files[j].Hour)
+		},
+	)
+	return files, nil
+}
+
+func fileFor(dir string, t time.Time) File {
+	hour := t.UTC().Truncate(time.Hour)
+	return File{
+		Hour: hour,
+		Path: filepath.Join(dir, hour.Format(hourLayout)+dataSuffix),
+	}
+}
@@ -0,0 +1,82 @@
+package journal
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, 0)
+	assert.Nil(t, err)
+
+	start := time.Date(2026, 10, 18, 13, 58, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		err = w.write(
+			record{
+				channel: "market",
+				message: map[string]int{"n": i},
+				time:    start.Add(time.Duration(i) * time.Minute),
+			},
+		)
+		assert.Nil(t, err)
+	}
+	w.close()
+
+	// Two hours were written
+	files, err := Files(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+
+	// Start in the middle of the second file
+	r, err := NewReader(dir, start.Add(3*time.Minute))
+	assert.Nil(t, err)
+	var seqs []uint64
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		seqs = append(seqs, e.Seq)
+	}
+	assert.Equal(t, []uint64{4, 5, 6}, seqs)
+
+	// The sequence carries on after a restart
+	w, err = NewWriter(dir, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), w.seq)
+}
+
+func TestWriteFailure(t *testing.T) {
+	w, err := NewWriter(t.TempDir(), 0)
+	assert.Nil(t, err)
+
+	now := time.Now().UTC()
+	entry := record{channel: "market", message: "m", time: now}
+	assert.Nil(t, w.write(entry))
+
+	// A failed write leaves no gap in the sequence
+	assert.Nil(t, w.data.Close())
+	assert.NotNil(t, w.write(entry))
+	assert.Equal(t, uint64(1), w.seq)
+
+	w.data = nil
+	assert.Nil(t, w.write(entry))
+	assert.Equal(t, uint64(2), w.seq)
+	w.close()
+}
+
+func TestPublishDoesNotBlock(t *testing.T) {
+	w, err := NewWriter(t.TempDir(), 0)
+	assert.Nil(t, err)
+
+	// Nothing runs the writer, the queue fills and the rest are dropped
+	for i := 0; i < cap(w.queue)+10; i++ {
+		w.Publish("market", i)
+	}
+	assert.Len(t, w.queue, cap(w.queue))
+}
@@ -0,0 +1,126 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Reader reads entries in order across the journal files
+type Reader struct {
+	files []File
+	from  time.Time
+
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+// NewReader reads a journal from the first entry at or after a time.
+// A zero time reads everything.
+func NewReader(dir string, from time.Time) (*Reader, error) {
+	files, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip whole hours before the start
+	start := from.UTC().Truncate(time.Hour)
+	for len(files) > 0 && files[0].Hour.Before(start) {
+		files = files[1:]
+	}
+	return &Reader{files: files, from: from}, nil
+}
+
+// Next returns the next entry, or io.EOF at the end of the journal
+func (r *Reader) Next() (Entry, error) {
+	for {
+		if r.scanner == nil {
+			if len(r.files) == 0 {
+				return Entry{}, io.EOF
+			}
+			if err := r.open(r.files[0]); err != nil {
+				return Entry{}, err
+			}
+			r.files = r.files[1:]
+		}
+
+		if !r.scanner.Scan() {
+			err := r.scanner.Err()
+			r.closeFile()
+			// A file being written or cut short by a crash ends early
+			if err != nil && err != io.ErrUnexpectedEOF {
+				return Entry{}, err
+			}
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(r.scanner.Bytes(), &e); err != nil {
+			return Entry{}, err
+		}
+		if e.Time.Before(r.from) {
+			continue
+		}
+		return e, nil
+	}
+}
+
+// Close the reader
+func (r *Reader) Close() error {
+	r.closeFile()
+	return nil
+}
+
+// open a file, seeking to the start time with its index
+func (r *Reader) open(f File) error {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+
+	if index, err := f.Index(); err == nil {
+		for _, e := range index {
+			if !e.Time.Before(r.from) {
+				if _, err = file.Seek(e.Offset, io.SeekStart); err != nil {
+					_ = file.Close()
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err == io.EOF {
+		// Empty file
+		r.file = file
+		r.scanner = bufio.NewScanner(eofReader{})
+		return nil
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.file = file
+	r.scanner = bufio.NewScanner(zr)
+	r.scanner.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	return nil
+}
+
+func (r *Reader) closeFile() {
+	if r.file != nil {
+		_ = r.file.Close()
+	}
+	r.file = nil
+	r.scanner = nil
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
@@ -0,0 +1,284 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+)
+
+type record struct {
+	channel string
+	message interface{}
+	time    time.Time
+}
+
+// Writer appends broadcasts to the journal
+type Writer struct {
+	dir       string
+	retention time.Duration
+	log       *slog.Logger
+
+	queue chan record
+	seq   uint64
+
+	// current hour
+	file   File
+	data   *os.File
+	index  *os.File
+	offset int64
+}
+
+// NewWriter opens a journal in a directory. Files older than the retention
+// are removed on rotation, a retention of zero keeps everything.
+func NewWriter(dir string, retention time.Duration) (*Writer, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		dir:       dir,
+		retention: retention,
+		log:       logging.For("journal"),
+		queue:     make(chan record, 4096),
+	}
+
+	// Carry on the sequence from the last run
+	files, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		index, err := files[i].Index()
+		if err != nil || len(index) == 0 {
+			continue
+		}
+		w.seq = index[len(index)-1].Seq
+		break
+	}
+	return w, nil
+}
+
+// Publish queues a broadcast for the journal. It is called on the hub
+// goroutine, so it never blocks: when the writer falls thousands of messages
+// behind, as on a slow or full disk, broadcasts are dropped and counted.
+func (w *Writer) Publish(channel string, message interface{}) {
+	select {
+	case w.queue <- record{channel: channel, message: message, time: time.Now().UTC()}:
+	default:
+		metricJournalDropped.Inc()
+	}
+}
+
+// Run writes queued broadcasts
+func (w *Writer) Run(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#journal-writer")
+		},
+	)
+	w.log = logging.WithHub(w.log, localHub)
+
+	for r := range w.queue {
+		err := w.write(r)
+		if err != nil {
+			w.log.Error("writing an entry", logging.Error, err)
+			metricJournalErrors.Inc()
+		}
+	}
+}
+
+// write an entry, rotating the file on the hour
+func (w *Writer) write(r record) error {
+	message, err := json.Marshal(r.message)
+	if err != nil {
+		return err
+	}
+
+	if w.data == nil || !fileFor(w.dir, r.time).Hour.Equal(w.file.Hour) {
+		if err = w.rotate(r.time); err != nil {
+			return err
+		}
+	}
+
+	// The sequence only moves on once the entry is written
+	seq := w.seq + 1
+	entry, err := json.Marshal(
+		Entry{
+			Seq:     seq,
+			Time:    r.time,
+			Channel: r.channel,
+			Message: message,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Each entry is its own gzip member
+	counter := &countingWriter{w: bufio.NewWriter(w.data)}
+	zw := gzip.NewWriter(counter)
+	if _, err = zw.Write(append(entry, '\n')); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = counter.w.Flush(); err != nil {
+		return err
+	}
+
+	index, err := json.Marshal(
+		IndexEntry{
+			Seq:     seq,
+			Time:    r.time,
+			Channel: r.channel,
+			Offset:  w.offset,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if _, err = w.index.Write(append(index, '\n')); err != nil {
+		return err
+	}
+
+	w.seq = seq
+	w.offset += counter.n
+	metricJournalBytes.Add(float64(counter.n))
+	metricJournalEntries.Inc()
+	return nil
+}
+
+// rotate to the file of the hour, removing expired files
+func (w *Writer) rotate(t time.Time) error {
+	w.close()
+
+	file := fileFor(w.dir, t)
+	data, err := os.OpenFile(file.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	index, err := os.OpenFile(file.IndexPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640) //nolint:gosec
+	if err != nil {
+		_ = data.Close()
+		return err
+	}
+	info, err := data.Stat()
+	if err != nil {
+		_ = data.Close()
+		_ = index.Close()
+		return err
+	}
+
+	w.file = file
+	w.data = data
+	w.index = index
+	w.offset = info.Size()
+
+	w.expire(t)
+	return nil
+}
+
+func (w *Writer) close() {
+	for _, f := range []*os.File{w.data, w.index} {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil {
+			w.log.Error("closing the journal", logging.Error, err)
+		}
+	}
+	w.data = nil
+	w.index = nil
+}
+
+// expire files past the retention
+func (w *Writer) expire(now time.Time) {
+	if w.retention <= 0 {
+		return
+	}
+	files, err := Files(w.dir)
+	if err != nil {
+		w.log.Error("listing the journal", logging.Error, err)
+		return
+	}
+	for _, f := range files {
+		// Keep any file with entries inside the retention
+		if now.Sub(f.Hour.Add(time.Hour)) <= w.retention {
+			break
+		}
+		for _, p := range []string{f.Path, f.IndexPath()} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				w.log.Error("removing an expired journal", "path", p, logging.Error, err)
+			}
+		}
+		w.log.Info("removed expired journal", "path", f.Path)
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Metrics
+var (
+	metricJournalEntries = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "journal",
+			Name:      "entries",
+			Help:      "Count of journal entries written.",
+		},
+	)
+
+	metricJournalBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "journal",
+			Name:      "bytes",
+			Help:      "Compressed bytes written to the journal.",
+		},
+	)
+
+	metricJournalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "journal",
+			Name:      "errors",
+			Help:      "Count of journal write errors.",
+		},
+	)
+
+	metricJournalDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "journal",
+			Name:      "dropped",
+			Help:      "Count of broadcasts dropped because the journal fell behind.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricJournalEntries,
+		metricJournalBytes,
+		metricJournalErrors,
+		metricJournalDropped,
+	)
+}
@@ -0,0 +1,221 @@
+// Package logging configures the structured JSON logs of the service.
+//
+// Every subsystem gets its own logger, tagged with a subsystem field, so its
+// level can be set apart from the others with LOG_LEVEL, e.g.
+// "info,esi=debug,wsbroadcast=warn". Errors logged with an error field are
+// also sent to Sentry, so callers log them once and do not capture them too.
+// Goroutines with a hub of their own pass it to WithHub so the tags set on
+// it go along.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+	"golang.org/x/exp/slog"
+)
+
+// Standard fields
+const (
+	Subsystem  = "subsystem"
+	RegionID   = "region_id"
+	Channel    = "channel"
+	CycleID    = "cycle_id"
+	Page       = "page"
+	ClientAddr = "client_addr"
+	Error      = "error"
+)
+
+var (
+	mutex sync.Mutex
+
+	// base handler all subsystems write to
+	base slog.Handler = slog.HandlerOptions{Level: slog.LevelDebug}.NewJSONHandler(os.Stderr)
+
+	// level of subsystems without their own
+	defaultLevel slog.Level = slog.LevelInfo
+
+	// levels by subsystem
+	levels = make(map[string]*slog.LevelVar)
+)
+
+// Setup writes the logs to w at the levels of spec, a default level and
+// subsystem=level pairs separated by commas. The standard library logger
+// writes through it too, at the info level.
+func Setup(w io.Writer, spec string) error {
+	def, bySubsystem, err := parseLevels(spec)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	base = slog.HandlerOptions{Level: slog.LevelDebug}.NewJSONHandler(w)
+	defaultLevel = def
+	for name, v := range levels {
+		v.Set(levelOf(name, bySubsystem))
+	}
+	for name, l := range bySubsystem {
+		if _, ok := levels[name]; !ok {
+			levels[name] = &slog.LevelVar{}
+			levels[name].Set(l)
+		}
+	}
+	mutex.Unlock()
+
+	slog.SetDefault(For("default"))
+	return nil
+}
+
+// For returns the logger of a subsystem. Loggers write to the output set
+// up when they are made, so Setup comes first.
+func For(subsystem string) *slog.Logger {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	level, ok := levels[subsystem]
+	if !ok {
+		level = &slog.LevelVar{}
+		level.Set(defaultLevel)
+		levels[subsystem] = level
+	}
+	handler := &levelHandler{level: level, next: &sentryHandler{next: base}}
+	return slog.New(handler).With(Subsystem, subsystem)
+}
+
+// WithHub returns a logger capturing its errors in hub, with the tags
+// configured on it, rather than in the current hub
+func WithHub(l *slog.Logger, hub *sentry.Hub) *slog.Logger {
+	return slog.New(withHub(l.Handler(), hub))
+}
+
+func withHub(h slog.Handler, hub *sentry.Hub) slog.Handler {
+	switch h := h.(type) {
+	case *levelHandler:
+		return &levelHandler{level: h.level, next: withHub(h.next, hub)}
+	case *sentryHandler:
+		return &sentryHandler{next: h.next, attrs: h.attrs, hub: hub}
+	}
+	return h
+}
+
+func levelOf(subsystem string, bySubsystem map[string]slog.Level) slog.Level {
+	if l, ok := bySubsystem[subsystem]; ok {
+		return l
+	}
+	return defaultLevel
+}
+
+// parseLevels of a LOG_LEVEL spec, info when empty
+func parseLevels(spec string) (slog.Level, map[string]slog.Level, error) {
+	def := slog.LevelInfo
+	bySubsystem := make(map[string]slog.Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, raw, isPair := strings.Cut(part, "=")
+		if !isPair {
+			raw = name
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(raw)); err != nil {
+			return def, nil, fmt.Errorf("log level %q: %w", part, err)
+		}
+		if isPair {
+			bySubsystem[name] = l
+		} else {
+			def = l
+		}
+	}
+	return def, bySubsystem, nil
+}
+
+// levelHandler drops the records below the level of its subsystem
+type levelHandler struct {
+	level slog.Leveler
+	next  slog.Handler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithGroup(name)}
+}
+
+// sentryHandler captures error records in its hub, or the current one,
+// tagged with their fields
+type sentryHandler struct {
+	next  slog.Handler
+	attrs []slog.Attr
+	hub   *sentry.Hub
+}
+
+func (h *sentryHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *sentryHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		hub := h.hub
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+		capture(hub, r, h.attrs)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *sentryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sentryHandler{
+		next:  h.next.WithAttrs(attrs),
+		attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+		hub:   h.hub,
+	}
+}
+
+func (h *sentryHandler) WithGroup(name string) slog.Handler {
+	return &sentryHandler{next: h.next.WithGroup(name), attrs: h.attrs, hub: h.hub}
+}
+
+// capture a record in Sentry, as an exception when it has an error field
+func capture(parent *sentry.Hub, r slog.Record, attrs []slog.Attr) {
+	var err error
+	hub := parent.Clone()
+	hub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			tag := func(a slog.Attr) {
+				if e, ok := a.Value.Any().(error); ok && a.Key == Error {
+					err = e
+					return
+				}
+				scope.SetTag(a.Key, a.Value.String())
+			}
+			for _, a := range attrs {
+				tag(a)
+			}
+			r.Attrs(tag)
+		},
+	)
+
+	if err != nil {
+		hub.CaptureException(err)
+		return
+	}
+	hub.CaptureMessage(r.Message)
+}
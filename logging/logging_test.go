@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+)
+
+func TestLevels(t *testing.T) {
+	def, bySubsystem, err := parseLevels("warn, esi=debug,wsbroadcast=error")
+	assert.Nil(t, err)
+	assert.Equal(t, slog.LevelWarn, def)
+	assert.Equal(t, map[string]slog.Level{"esi": slog.LevelDebug, "wsbroadcast": slog.LevelError}, bySubsystem)
+
+	_, _, err = parseLevels("esi=loud")
+	assert.NotNil(t, err)
+
+	var out bytes.Buffer
+	assert.Nil(t, Setup(&out, "warn,esi=debug"))
+
+	For("esi").Debug("request", RegionID, 10000002)
+	For("marketwatch").Info("dropped")
+	For("marketwatch").Warn("kept", CycleID, 3)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var first map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "esi", first[Subsystem])
+	assert.Equal(t, "DEBUG", first["level"])
+	assert.Equal(t, float64(10000002), first[RegionID])
+
+	var second map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "marketwatch", second[Subsystem])
+	assert.Equal(t, "kept", second["msg"])
+}
+
+// transport keeps the events instead of sending them
+type transport struct {
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool       { return true }
+func (t *transport) Configure(sentry.ClientOptions) {}
+func (t *transport) SendEvent(event *sentry.Event)  { t.events = append(t.events, event) }
+
+func TestWithHub(t *testing.T) {
+	assert.Nil(t, Setup(io.Discard, "info"))
+
+	sent := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.invalid/1", Transport: sent})
+	assert.Nil(t, err)
+	hub := sentry.NewHub(client, sentry.NewScope())
+	hub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#test")
+		},
+	)
+
+	logger := WithHub(For("marketwatch"), hub).With(RegionID, 10000002)
+	logger.Info("not sent")
+	logger.Error("fetching", Error, errors.New("boom"))
+
+	assert.Len(t, sent.events, 1)
+	event := sent.events[0]
+	assert.Equal(t, "go#test", event.Tags["locationHash"])
+	assert.Equal(t, "10000002", event.Tags[RegionID])
+	assert.Equal(t, "marketwatch", event.Tags[Subsystem])
+	assert.Equal(t, "boom", event.Exception[0].Value)
+
+	// The hub itself is left as it was
+	hub.CaptureMessage("direct")
+	assert.Len(t, sent.events, 2)
+	assert.NotContains(t, sent.events[1].Tags, RegionID)
+}
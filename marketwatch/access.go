@@ -0,0 +1,228 @@
+package marketwatch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/contorno/eve-marketwatch/auth"
+	"github.com/contorno/eve-marketwatch/rules"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/contorno/goesi/esi"
+)
+
+// authorizer grants stream access to the keys of a keyring
+func authorizer(keys *auth.Keyring) wsbroadcast.AuthorizeFunc {
+	return func(r *http.Request) (*wsbroadcast.Grant, error) {
+		key, release, err := keys.Authorize(r)
+		if errors.Is(err, auth.ErrTooManyConnections) {
+			return nil, &wsbroadcast.Refusal{Status: http.StatusTooManyRequests, Err: err}
+		}
+		if err != nil {
+			return nil, &wsbroadcast.Refusal{Status: http.StatusUnauthorized, Err: err}
+		}
+
+		grant := &wsbroadcast.Grant{
+			Name:    key.Name,
+			Allowed: key.Allows,
+			Release: release,
+		}
+		if len(key.Regions) > 0 || len(key.Locations) > 0 {
+			grant.Filter = newScope(key).filter
+		}
+		return grant, nil
+	}
+}
+
+// guard an api with the keys allowed on channel, open when there are no keys.
+// The key is passed on in the request context for scopeOf.
+func (s *MarketWatch) guard(channel string, next http.HandlerFunc) http.HandlerFunc {
+	if s.keys == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, release, err := s.keys.Authorize(r)
+		if errors.Is(err, auth.ErrTooManyConnections) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		defer release()
+
+		if !key.Allows(channel) {
+			http.Error(w, "channel not allowed for this api key", http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(withKey(r.Context(), key)))
+	}
+}
+
+type keyContext struct{}
+
+// withKey passes the key of a request on to its handler
+func withKey(ctx context.Context, key *auth.Key) context.Context {
+	return context.WithValue(ctx, keyContext{}, key)
+}
+
+// keyOf a request, nil when access is open
+func keyOf(ctx context.Context) *auth.Key {
+	key, _ := ctx.Value(keyContext{}).(*auth.Key)
+	return key
+}
+
+// scopeOf a request, nil when it sees everything
+func scopeOf(ctx context.Context) *scope {
+	key := keyOf(ctx)
+	if key == nil || (len(key.Regions) == 0 && len(key.Locations) == 0) {
+		return nil
+	}
+	return newScope(key)
+}
+
+// readOnly refuses anything but GET
+func readOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// scope of the regions and locations a key sees. A nil scope sees everything.
+type scope struct {
+	regions   map[int64]bool
+	locations map[int64]bool
+}
+
+func newScope(key *auth.Key) *scope {
+	sc := &scope{}
+	if len(key.Regions) > 0 {
+		sc.regions = make(map[int64]bool)
+		for _, r := range key.Regions {
+			sc.regions[r] = true
+		}
+	}
+	if len(key.Locations) > 0 {
+		sc.locations = make(map[int64]bool)
+		for _, l := range key.Locations {
+			sc.locations[l] = true
+		}
+	}
+	return sc
+}
+
+// filter a broadcast or dump message to the scope
+func (sc *scope) filter(_ string, m interface{}) interface{} {
+	switch msg := m.(type) {
+	case *wsbroadcast.OptionalMessage:
+		if sc.filterMessage(msg.For(nil)) == nil {
+			return nil
+		}
+		return msg.Map(sc.filterMessage)
+	default:
+		return sc.filterMessage(m)
+	}
+}
+
+// filterMessage drops what is not a Message, as its scope is not known
+func (sc *scope) filterMessage(m interface{}) interface{} {
+	msg, ok := m.(Message)
+	if !ok {
+		return nil
+	}
+	if msg.RegionID != 0 && !sc.region(msg.RegionID) {
+		return nil
+	}
+	payload := sc.filterPayload(msg.Payload)
+	if payload == nil {
+		return nil
+	}
+	msg.Payload = payload
+	return msg
+}
+
+// filterPayload keeps the entries in scope, nil when none are or the payload
+// is of a type it does not know
+func (sc *scope) filterPayload(payload interface{}) interface{} {
+	switch p := payload.(type) {
+	case []esi.GetMarketsRegionIdOrders200Ok:
+		return keep(p, func(o esi.GetMarketsRegionIdOrders200Ok) bool { return sc.location(o.LocationId) })
+	case []EnrichedOrder:
+		return keep(p, func(o EnrichedOrder) bool { return sc.location(o.LocationId) })
+	case []OrderChange:
+		return keep(p, func(c OrderChange) bool { return sc.location(c.LocationId) })
+	case []EnrichedOrderChange:
+		return keep(p, func(c EnrichedOrderChange) bool { return sc.location(c.LocationId) })
+	case []FullContract:
+		return keep(p, func(c FullContract) bool { return sc.location(c.Contract.StartLocationId) })
+	case []EnrichedContract:
+		return keep(p, func(c EnrichedContract) bool { return sc.location(c.Contract.StartLocationId) })
+	case []ContractChange:
+		return keep(p, func(c ContractChange) bool { return sc.location(c.LocationId) })
+	case []EnrichedContractChange:
+		return keep(p, func(c EnrichedContractChange) bool { return sc.location(c.LocationId) })
+	case []SnapshotRegion:
+		return keep(p, func(r SnapshotRegion) bool { return sc.region(r.RegionID) })
+	case []rules.Alert:
+		return keep(
+			p, func(a rules.Alert) bool {
+				if !sc.region(a.RegionID) {
+					return false
+				}
+				location, ok := payloadLocation(a.Payload)
+				return sc.locations == nil || (ok && sc.location(location))
+			},
+		)
+	case CycleStatus:
+		if !sc.region(p.RegionID) {
+			return nil
+		}
+		return p
+	case Heartbeat:
+		return p
+	}
+	return nil
+}
+
+func (sc *scope) region(regionID int64) bool {
+	return sc == nil || sc.regions == nil || sc.regions[regionID]
+}
+
+func (sc *scope) location(locationID int64) bool {
+	return sc == nil || sc.locations == nil || sc.locations[locationID]
+}
+
+// payloadLocation of an alert payload
+func payloadLocation(payload interface{}) (int64, bool) {
+	switch p := payload.(type) {
+	case esi.GetMarketsRegionIdOrders200Ok:
+		return p.LocationId, true
+	case OrderChange:
+		return p.LocationId, true
+	case FullContract:
+		return p.Contract.StartLocationId, true
+	case ContractChange:
+		return p.LocationId, true
+	}
+	return 0, false
+}
+
+// keep the entries of a list that pass, nil when none do
+func keep[T any](list []T, pass func(T) bool) interface{} {
+	var kept []T
+	for _, v := range list {
+		if pass(v) {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
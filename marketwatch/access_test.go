@@ -0,0 +1,132 @@
+package marketwatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contorno/eve-marketwatch/auth"
+	"github.com/contorno/eve-marketwatch/rules"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/contorno/goesi/esi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeFilter(t *testing.T) {
+	sc := newScope(&auth.Key{Regions: []int64{1}, Locations: []int64{100}})
+
+	orders := Message{
+		Action:   "addition",
+		RegionID: 1,
+		Payload:  []esi.GetMarketsRegionIdOrders200Ok{{OrderId: 1, LocationId: 100}, {OrderId: 2, LocationId: 200}},
+	}
+	kept := sc.filter("market", orders).(Message)
+	assert.Equal(t, []esi.GetMarketsRegionIdOrders200Ok{{OrderId: 1, LocationId: 100}}, kept.Payload)
+
+	// Other regions and locations
+	orders.RegionID = 2
+	assert.Nil(t, sc.filter("market", orders))
+	assert.Nil(
+		t, sc.filter(
+			"contract", Message{
+				Action:   "contractChange",
+				RegionID: 1,
+				Payload:  []ContractChange{{ContractId: 1, LocationId: 200}},
+			},
+		),
+	)
+
+	// Status of the regions in scope, and heartbeats
+	assert.NotNil(t, sc.filter("status", Message{Action: "cycleComplete", RegionID: 1, Payload: CycleStatus{RegionID: 1}}))
+	assert.Nil(t, sc.filter("status", Message{Action: "cycleComplete", Payload: CycleStatus{RegionID: 2}}))
+	assert.NotNil(t, sc.filter("status", Message{Action: "heartbeat", Payload: Heartbeat{}}))
+
+	// Dumps list the regions in scope
+	regions := sc.filter(
+		"", Message{Action: "snapshotBegin", Payload: []SnapshotRegion{{RegionID: 1}, {RegionID: 2}}},
+	).(Message)
+	assert.Equal(t, []SnapshotRegion{{RegionID: 1}}, regions.Payload)
+
+	// Alerts by region and by the location of what matched
+	alerts := sc.filter(
+		"alerts", Message{
+			Action: "alert",
+			Payload: []rules.Alert{
+				{RuleID: "in", RegionID: 1, Payload: OrderChange{LocationId: 100}},
+				{RuleID: "elsewhere", RegionID: 1, Payload: OrderChange{LocationId: 200}},
+				{RuleID: "other region", RegionID: 2, Payload: OrderChange{LocationId: 100}},
+				{RuleID: "unknown", RegionID: 1, Payload: "?"},
+			},
+		},
+	).(Message)
+	assert.Len(t, alerts.Payload, 1)
+	assert.Equal(t, "in", alerts.Payload.([]rules.Alert)[0].RuleID)
+
+	// Payloads and values the scope does not know are held back
+	assert.Nil(t, sc.filter("market", Message{Action: "addition", RegionID: 1, Payload: []string{"?"}}))
+	assert.Nil(t, sc.filter("market", "sup"))
+
+	// Both forms of optional messages
+	optional := sc.filter(
+		"market", enrichedMessage(
+			Message{
+				Action:   "change",
+				RegionID: 1,
+				Payload:  []OrderChange{{OrderID: 1, LocationId: 100}, {OrderID: 2, LocationId: 200}},
+			},
+			func() interface{} {
+				return []EnrichedOrderChange{
+					{OrderChange: OrderChange{OrderID: 1, LocationId: 100}},
+					{OrderChange: OrderChange{OrderID: 2, LocationId: 200}},
+				}
+			},
+		),
+	).(*wsbroadcast.OptionalMessage)
+	assert.Len(t, optional.For(nil).(Message).Payload, 1)
+	assert.Len(t, optional.For(map[string]bool{enrichOption: true}).(Message).Payload, 1)
+}
+
+func TestGuard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	err := os.WriteFile(
+		path, []byte(`[
+			{"name": "all", "key": "all-secret"},
+			{"name": "market", "key": "market-secret", "channels": ["market"]},
+			{"name": "jita", "key": "jita-secret", "regions": [10000002]}
+		]`), 0o600,
+	)
+	assert.Nil(t, err)
+	keys, err := auth.NewKeyring(path)
+	assert.Nil(t, err)
+
+	var seen *scope
+	s := &MarketWatch{keys: keys}
+	handler := s.guard(
+		"contract", func(w http.ResponseWriter, r *http.Request) {
+			seen = scopeOf(r.Context())
+		},
+	)
+	status := func(key string) int {
+		r := httptest.NewRequest(http.MethodGet, "/contracts/search", nil)
+		if key != "" {
+			r.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, status(""))
+	assert.Equal(t, http.StatusUnauthorized, status("wrong"))
+	assert.Equal(t, http.StatusForbidden, status("market-secret"))
+
+	assert.Equal(t, http.StatusOK, status("all-secret"))
+	assert.Nil(t, seen)
+
+	assert.Equal(t, http.StatusOK, status("jita-secret"))
+	assert.NotNil(t, seen)
+	assert.True(t, seen.region(10000002))
+	assert.False(t, seen.region(10000043))
+}
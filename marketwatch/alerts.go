@@ -0,0 +1,158 @@
+package marketwatch
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/rules"
+	"github.com/contorno/goesi/esi"
+)
+
+// checkOrders against the rules
+func (s *MarketWatch) checkOrders(regionID int64, action string, orders []esi.GetMarketsRegionIdOrders200Ok) {
+	if s.rules.Empty() {
+		return
+	}
+	var alerts []rules.Alert
+	for _, o := range orders {
+		alerts = append(
+			alerts, s.rules.Check(
+				rules.Subject{
+					Kind:       rules.KindOrder,
+					Action:     action,
+					RegionID:   regionID,
+					LocationID: o.LocationId,
+					TypeIDs:    []int32{o.TypeId},
+					IsBuyOrder: o.IsBuyOrder,
+					Price:      o.Price,
+				}, o,
+			)...,
+		)
+	}
+	s.broadcastAlerts(alerts)
+}
+
+// checkOrderChanges against the rules
+func (s *MarketWatch) checkOrderChanges(regionID int64, action string, changes []OrderChange) {
+	if s.rules.Empty() {
+		return
+	}
+	var alerts []rules.Alert
+	for _, c := range changes {
+		alerts = append(
+			alerts, s.rules.Check(
+				rules.Subject{
+					Kind:       rules.KindOrder,
+					Action:     action,
+					RegionID:   regionID,
+					LocationID: c.LocationId,
+					TypeIDs:    []int32{c.TypeID},
+					IsBuyOrder: c.IsBuyOrder,
+					Price:      c.Price,
+				}, c,
+			)...,
+		)
+	}
+	s.broadcastAlerts(alerts)
+}
+
+// checkContracts against the rules
+func (s *MarketWatch) checkContracts(regionID int64, action string, contracts []FullContract) {
+	if s.rules.Empty() {
+		return
+	}
+	var alerts []rules.Alert
+	for _, c := range contracts {
+		alerts = append(
+			alerts, s.rules.Check(
+				rules.Subject{
+					Kind:         rules.KindContract,
+					Action:       action,
+					RegionID:     regionID,
+					LocationID:   c.Contract.StartLocationId,
+					TypeIDs:      itemTypes(c.Items),
+					ContractType: c.Contract.Type_,
+					Price:        askingPrice(&c),
+				}, c,
+			)...,
+		)
+	}
+	s.broadcastAlerts(alerts)
+}
+
+// checkContractChanges against the rules
+func (s *MarketWatch) checkContractChanges(regionID int64, action string, changes []ContractChange) {
+	if s.rules.Empty() {
+		return
+	}
+	var alerts []rules.Alert
+	for _, c := range changes {
+		alerts = append(
+			alerts, s.rules.Check(
+				rules.Subject{
+					Kind:         rules.KindContract,
+					Action:       action,
+					RegionID:     regionID,
+					LocationID:   c.LocationId,
+					TypeIDs:      itemTypes(c.Items),
+					ContractType: c.Type_,
+					Price:        c.Price,
+				}, c,
+			)...,
+		)
+	}
+	s.broadcastAlerts(alerts)
+}
+
+func (s *MarketWatch) broadcastAlerts(alerts []rules.Alert) {
+	if len(alerts) == 0 {
+		return
+	}
+	s.broadcast.Broadcast(
+		"alerts", Message{
+			Action:  "alert",
+			Payload: alerts,
+		},
+	)
+}
+
+// itemTypes offered by a contract
+func itemTypes(items []esi.GetContractsPublicItemsContractId200Ok) []int32 {
+	var types []int32
+	for _, item := range items {
+		if item.IsIncluded {
+			types = append(types, item.TypeId)
+		}
+	}
+	return types
+}
+
+// serveAlerts lists the stored alerts in the scope of the key asking
+func (s *MarketWatch) serveAlerts(w http.ResponseWriter, r *http.Request) {
+	sc := scopeOf(r.Context())
+	if sc == nil {
+		s.rules.ServeAlerts(w, r)
+		return
+	}
+	q, err := rules.ParseAlertQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Limit once the alerts out of scope are gone
+	found := []rules.Alert{}
+	if kept, ok := sc.filterPayload(s.rules.Alerts(q.RuleID, q.Since, 0)).([]rules.Alert); ok {
+		found = kept
+	}
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[len(found)-q.Limit:]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(found)
+	if err != nil {
+		s.log.Error("writing alerts", logging.Error, err)
+	}
+}
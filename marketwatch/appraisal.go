@@ -0,0 +1,194 @@
+package marketwatch
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Jita IV - Moon 4 - Caldari Navy Assembly Plant
+const defaultAppraisalHub = 60003760
+
+// Contracts priced under this share of their value are deals.
+const defaultDealRatio = 0.7
+
+// itemPrice is the best price for a type at the reference hubs
+type itemPrice struct {
+	Buy  float64
+	Sell float64
+}
+
+// priceBook keeps the best hub prices per region
+type priceBook struct {
+	hubs   map[int64]bool
+	mutex  sync.RWMutex
+	prices map[int64]map[int32]itemPrice // regionID -> typeID -> price
+}
+
+func newPriceBook(hubs []int64) *priceBook {
+	p := &priceBook{
+		hubs:   make(map[int64]bool),
+		prices: make(map[int64]map[int32]itemPrice),
+	}
+	for _, h := range hubs {
+		p.hubs[h] = true
+	}
+	return p
+}
+
+// update the prices of a region from its market store
+func (p *priceBook) update(regionID int64, sMap *sync.Map) {
+	prices := make(map[int32]itemPrice)
+	sMap.Range(
+		func(k, v interface{}) bool {
+			o := v.(Order).Order
+			if !p.hubs[o.LocationId] {
+				return true
+			}
+			price := prices[o.TypeId]
+			if o.IsBuyOrder {
+				if o.Price > price.Buy {
+					price.Buy = o.Price
+				}
+			} else if price.Sell == 0 || o.Price < price.Sell {
+				price.Sell = o.Price
+			}
+			prices[o.TypeId] = price
+			return true
+		},
+	)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(prices) == 0 {
+		delete(p.prices, regionID)
+		return
+	}
+	p.prices[regionID] = prices
+}
+
+// price finds the best buy and sell for a type across all hubs
+func (p *priceBook) price(typeID int32) itemPrice {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var best itemPrice
+	for _, prices := range p.prices {
+		price, ok := prices[typeID]
+		if !ok {
+			continue
+		}
+		if price.Buy > best.Buy {
+			best.Buy = price.Buy
+		}
+		if price.Sell != 0 && (best.Sell == 0 || price.Sell < best.Sell) {
+			best.Sell = price.Sell
+		}
+	}
+	return best
+}
+
+// appraise sets the estimated value and price ratio of item exchanges and auctions.
+// Items are valued at the best hub sell price, falling back to the best buy price,
+// and items asked for by the issuer are taken off the value. Blueprint copies are
+// not valued, and a contract asking for one, or for an item without a price, has
+// no price ratio since what it costs can not be told.
+func (p *priceBook) appraise(c *FullContract) {
+	if c.Contract.Type_ != "item_exchange" && c.Contract.Type_ != "auction" {
+		return
+	}
+
+	value := 0.0
+	known := true
+	for _, item := range c.Items {
+		if item.IsBlueprintCopy {
+			known = known && item.IsIncluded
+			continue
+		}
+		unit := p.unitValue(item.TypeId)
+		if item.IsIncluded {
+			value += unit * float64(item.Quantity)
+			continue
+		}
+		if unit == 0 {
+			known = false
+		}
+		value -= unit * float64(item.Quantity)
+	}
+
+	c.EstimatedValue = value
+	c.PriceRatio = 0
+	if price, ok := contractPrice(c); ok && known && value > 0 {
+		c.PriceRatio = price / value
+	}
+}
+
+// unitValue of a type: the best sell price, or the best buy price without sellers
+func (p *priceBook) unitValue(typeID int32) float64 {
+	price := p.price(typeID)
+	if price.Sell == 0 {
+		return price.Buy
+	}
+	return price.Sell
+}
+
+// isDeal checks if an appraised contract is priced well under value
+func isDeal(c FullContract, ratio float64) bool {
+	return c.EstimatedValue > 0 && c.PriceRatio > 0 && c.PriceRatio < ratio
+}
+
+// contractPrice is what a buyer pays to take the contract outright, which
+// auctions without a buyout have not
+func contractPrice(c *FullContract) (float64, bool) {
+	if c.Contract.Type_ != "auction" {
+		return c.Contract.Price, true
+	}
+	if c.Contract.Buyout > 0 {
+		return c.Contract.Buyout, true
+	}
+	return 0, false
+}
+
+// askingPrice of a contract for the rules: the price to take it outright,
+// or the highest bid of an auction without a buyout, its starting price
+// before any bids
+func askingPrice(c *FullContract) float64 {
+	if price, ok := contractPrice(c); ok {
+		return price
+	}
+	price := c.Contract.Price
+	for _, b := range c.Bids {
+		if float64(b.Amount) > price {
+			price = float64(b.Amount)
+		}
+	}
+	return price
+}
+
+// appraisalHubsFromEnv reads APPRAISAL_HUBS, a comma separated list of location IDs
+func appraisalHubsFromEnv() ([]int64, error) {
+	raw := os.Getenv("APPRAISAL_HUBS")
+	if raw == "" {
+		return []int64{defaultAppraisalHub}, nil
+	}
+
+	var hubs []int64
+	for _, h := range strings.Split(raw, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(h), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		hubs = append(hubs, id)
+	}
+	return hubs, nil
+}
+
+// dealRatioFromEnv reads DEAL_RATIO, the price to value ratio under which a contract is a deal
+func dealRatioFromEnv() (float64, error) {
+	raw := os.Getenv("DEAL_RATIO")
+	if raw == "" {
+		return defaultDealRatio, nil
+	}
+	return strconv.ParseFloat(raw, 64)
+}
@@ -0,0 +1,157 @@
+package marketwatch
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/contorno/goesi/esi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractPrice(t *testing.T) {
+	bids := []esi.GetContractsPublicBidsContractId200Ok{{Amount: 150}, {Amount: 120}}
+	tests := []struct {
+		name     string
+		contract FullContract
+		price    float64
+		outright bool
+		asking   float64
+	}{
+		{
+			name:     "item exchange",
+			contract: FullContract{Contract: esi.GetContractsPublicRegionId200Ok{Type_: "item_exchange", Price: 100}},
+			price:    100, outright: true, asking: 100,
+		},
+		{
+			name:     "auction with a buyout",
+			contract: FullContract{Contract: esi.GetContractsPublicRegionId200Ok{Type_: "auction", Price: 100, Buyout: 500}, Bids: bids},
+			price:    500, outright: true, asking: 500,
+		},
+		{
+			name:     "auction without a buyout",
+			contract: FullContract{Contract: esi.GetContractsPublicRegionId200Ok{Type_: "auction", Price: 100}},
+			asking:   100,
+		},
+		{
+			name:     "auction without a buyout, with bids",
+			contract: FullContract{Contract: esi.GetContractsPublicRegionId200Ok{Type_: "auction", Price: 100}, Bids: bids},
+			asking:   150,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				price, outright := contractPrice(&test.contract)
+				assert.Equal(t, test.price, price)
+				assert.Equal(t, test.outright, outright)
+				assert.Equal(t, test.asking, askingPrice(&test.contract))
+			},
+		)
+	}
+}
+
+func TestPriceBook(t *testing.T) {
+	p := newPriceBook([]int64{60003760, 60008494})
+
+	forge := &sync.Map{}
+	for i, o := range []esi.GetMarketsRegionIdOrders200Ok{
+		{TypeId: 34, LocationId: 60003760, Price: 5},
+		{TypeId: 34, LocationId: 60003760, Price: 4},
+		{TypeId: 34, LocationId: 60003760, Price: 3, IsBuyOrder: true},
+		{TypeId: 34, LocationId: 60003760, Price: 3.5, IsBuyOrder: true},
+		// Not a hub
+		{TypeId: 34, LocationId: 1, Price: 1},
+		{TypeId: 35, LocationId: 60003760, Price: 10, IsBuyOrder: true},
+	} {
+		forge.Store(int64(i), Order{Order: o})
+	}
+	p.update(10000002, forge)
+
+	domain := &sync.Map{}
+	domain.Store(int64(1), Order{Order: esi.GetMarketsRegionIdOrders200Ok{TypeId: 34, LocationId: 60008494, Price: 3.8}})
+	p.update(10000043, domain)
+
+	assert.Equal(t, itemPrice{Buy: 3.5, Sell: 3.8}, p.price(34))
+	assert.Equal(t, itemPrice{Buy: 10}, p.price(35))
+	assert.Equal(t, itemPrice{}, p.price(36))
+
+	// A region without hub orders no longer counts
+	p.update(10000043, &sync.Map{})
+	assert.Equal(t, itemPrice{Buy: 3.5, Sell: 4}, p.price(34))
+}
+
+func TestAppraise(t *testing.T) {
+	p := newPriceBook([]int64{60003760})
+	hub := &sync.Map{}
+	for i, o := range []esi.GetMarketsRegionIdOrders200Ok{
+		{TypeId: 34, LocationId: 60003760, Price: 5},
+		{TypeId: 35, LocationId: 60003760, Price: 10, IsBuyOrder: true},
+	} {
+		hub.Store(int64(i), Order{Order: o})
+	}
+	p.update(10000002, hub)
+
+	type item = esi.GetContractsPublicItemsContractId200Ok
+	contract := func(kind string, price float64, items ...item) FullContract {
+		return FullContract{Contract: esi.GetContractsPublicRegionId200Ok{Type_: kind, Price: price}, Items: items}
+	}
+
+	tests := []struct {
+		name     string
+		contract FullContract
+		value    float64
+		ratio    float64
+		deal     bool
+	}{
+		{"sell price", contract("item_exchange", 300, item{TypeId: 34, Quantity: 100, IsIncluded: true}), 500, 0.6, true},
+		{"buy price without sellers", contract("item_exchange", 50, item{TypeId: 35, Quantity: 10, IsIncluded: true}), 100, 0.5, true},
+		{"fair price", contract("item_exchange", 450, item{TypeId: 34, Quantity: 100, IsIncluded: true}), 500, 0.9, false},
+		{"no price", contract("item_exchange", 1, item{TypeId: 36, Quantity: 1, IsIncluded: true}), 0, 0, false},
+		{
+			"blueprint copy",
+			contract(
+				"item_exchange", 300, item{TypeId: 34, Quantity: 100, IsIncluded: true},
+				item{TypeId: 35, Quantity: 1, IsIncluded: true, IsBlueprintCopy: true},
+			),
+			500, 0.6, true,
+		},
+		{
+			"requested items",
+			contract(
+				"item_exchange", 300, item{TypeId: 34, Quantity: 100, IsIncluded: true},
+				item{TypeId: 35, Quantity: 10},
+			),
+			400, 0.75, false,
+		},
+		{
+			"requested more than offered",
+			contract("item_exchange", 1, item{TypeId: 34, Quantity: 10, IsIncluded: true}, item{TypeId: 35, Quantity: 10}),
+			-50, 0, false,
+		},
+		{
+			"requested blueprint copy",
+			contract(
+				"item_exchange", 100, item{TypeId: 34, Quantity: 100, IsIncluded: true},
+				item{TypeId: 35, Quantity: 1, IsBlueprintCopy: true},
+			),
+			500, 0, false,
+		},
+		{
+			"requested item without a price",
+			contract("item_exchange", 100, item{TypeId: 34, Quantity: 100, IsIncluded: true}, item{TypeId: 36, Quantity: 1}),
+			500, 0, false,
+		},
+		{"auction without a buyout", contract("auction", 1, item{TypeId: 34, Quantity: 100, IsIncluded: true}), 500, 0, false},
+		{"courier", contract("courier", 1, item{TypeId: 34, Quantity: 100, IsIncluded: true}), 0, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				p.appraise(&test.contract)
+				assert.InDelta(t, test.value, test.contract.EstimatedValue, 1e-9)
+				assert.InDelta(t, test.ratio, test.contract.PriceRatio, 1e-9)
+				assert.Equal(t, test.deal, isDeal(test.contract, defaultDealRatio))
+			},
+		)
+	}
+}
@@ -0,0 +1,102 @@
+package marketwatch
+
+import (
+	"sync"
+)
+
+// indexedItem is an item entry in the contract index
+type indexedItem struct {
+	RegionID           int64
+	Quantity           int32
+	IsBlueprintCopy    bool
+	MaterialEfficiency int32
+	TimeEfficiency     int32
+	Runs               int32
+}
+
+// contractIndex maps item type IDs to the contracts containing them
+type contractIndex struct {
+	mutex   sync.RWMutex
+	byType  map[int32]map[int32][]indexedItem // typeID -> contractID -> items
+	indexed map[int32][]int32                 // contractID -> typeIDs
+}
+
+func newContractIndex() *contractIndex {
+	return &contractIndex{
+		byType:  make(map[int32]map[int32][]indexedItem),
+		indexed: make(map[int32][]int32),
+	}
+}
+
+// add the items of a contract to the index, replacing any previous entries
+func (idx *contractIndex) add(regionID int64, c FullContract) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	contractID := c.Contract.ContractId
+	idx.removeLocked(contractID)
+
+	for _, item := range c.Items {
+		// Skip items the issuer is asking for
+		if !item.IsIncluded {
+			continue
+		}
+		contracts, ok := idx.byType[item.TypeId]
+		if !ok {
+			contracts = make(map[int32][]indexedItem)
+			idx.byType[item.TypeId] = contracts
+		}
+		if _, ok := contracts[contractID]; !ok {
+			idx.indexed[contractID] = append(idx.indexed[contractID], item.TypeId)
+		}
+		contracts[contractID] = append(
+			contracts[contractID], indexedItem{
+				RegionID:           regionID,
+				Quantity:           item.Quantity,
+				IsBlueprintCopy:    item.IsBlueprintCopy,
+				MaterialEfficiency: item.MaterialEfficiency,
+				TimeEfficiency:     item.TimeEfficiency,
+				Runs:               item.Runs,
+			},
+		)
+	}
+}
+
+// remove a contract from the index
+func (idx *contractIndex) remove(contractID int32) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.removeLocked(contractID)
+}
+
+// removeLocked drops every type the contract was indexed under
+func (idx *contractIndex) removeLocked(contractID int32) {
+	for _, typeID := range idx.indexed[contractID] {
+		contracts, ok := idx.byType[typeID]
+		if !ok {
+			continue
+		}
+		delete(contracts, contractID)
+		if len(contracts) == 0 {
+			delete(idx.byType, typeID)
+		}
+	}
+	delete(idx.indexed, contractID)
+}
+
+// find contract IDs, grouped by region, holding the type and matching the item filter
+func (idx *contractIndex) find(typeID int32, match func(indexedItem) bool) map[int32]int64 {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	found := make(map[int32]int64)
+	for contractID, items := range idx.byType[typeID] {
+		for _, item := range items {
+			if match(item) {
+				found[contractID] = item.RegionID
+				break
+			}
+		}
+	}
+	return found
+}
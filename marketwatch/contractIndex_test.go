@@ -0,0 +1,109 @@
+package marketwatch
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/contorno/eve-marketwatch/auth"
+	"github.com/contorno/goesi/esi"
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem = esi.GetContractsPublicItemsContractId200Ok
+
+func indexContract(id int32, items ...testItem) FullContract {
+	return FullContract{Contract: esi.GetContractsPublicRegionId200Ok{ContractId: id}, Items: items}
+}
+
+func anyItem(indexedItem) bool { return true }
+
+func TestContractIndex(t *testing.T) {
+	idx := newContractIndex()
+	idx.add(1, indexContract(10, testItem{TypeId: 34, IsIncluded: true}, testItem{TypeId: 35, IsIncluded: true}))
+	idx.add(2, indexContract(11, testItem{TypeId: 34, IsIncluded: true}, testItem{TypeId: 36}))
+
+	assert.Equal(t, map[int32]int64{10: 1, 11: 2}, idx.find(34, anyItem))
+	assert.Equal(t, map[int32]int64{10: 1}, idx.find(35, anyItem))
+	// Requested items are not indexed
+	assert.Empty(t, idx.find(36, anyItem))
+
+	// Replacing a contract drops the types it no longer holds
+	idx.add(1, indexContract(10, testItem{TypeId: 37, IsIncluded: true}))
+	assert.Equal(t, map[int32]int64{11: 2}, idx.find(34, anyItem))
+	assert.Empty(t, idx.find(35, anyItem))
+	assert.Equal(t, map[int32]int64{10: 1}, idx.find(37, anyItem))
+
+	idx.remove(10)
+	idx.remove(11)
+	idx.remove(12)
+	assert.Empty(t, idx.byType)
+	assert.Empty(t, idx.indexed)
+}
+
+func TestFindContracts(t *testing.T) {
+	s := &MarketWatch{
+		contracts:     make(map[int64]*sync.Map),
+		contractIndex: newContractIndex(),
+	}
+	s.createContractStore(1)
+	s.createContractStore(2)
+
+	add := func(regionID int64, c esi.GetContractsPublicRegionId200Ok, items ...testItem) {
+		s.storeContract(regionID, Contract{Contract: FullContract{Contract: c, Items: items}})
+	}
+	add(
+		1, esi.GetContractsPublicRegionId200Ok{ContractId: 1, Type_: "item_exchange", StartLocationId: 100, Price: 100},
+		testItem{TypeId: 34, Quantity: 1000, IsIncluded: true},
+	)
+	add(
+		1, esi.GetContractsPublicRegionId200Ok{ContractId: 2, Type_: "auction", StartLocationId: 101, Price: 200},
+		testItem{TypeId: 34, Quantity: 10, IsIncluded: true},
+		testItem{TypeId: 999, IsIncluded: true, IsBlueprintCopy: true, MaterialEfficiency: 10, TimeEfficiency: 20, Runs: 5},
+	)
+	add(
+		2, esi.GetContractsPublicRegionId200Ok{ContractId: 3, Type_: "item_exchange", StartLocationId: 200, Price: 300},
+		testItem{TypeId: 999, IsIncluded: true, MaterialEfficiency: 8, TimeEfficiency: 16},
+	)
+
+	bpc, bpo := true, false
+	tests := []struct {
+		name  string
+		query contractQuery
+		ids   []int32
+	}{
+		{"everything", contractQuery{}, []int32{1, 2, 3}},
+		{"type", contractQuery{TypeID: 34}, []int32{1, 2}},
+		{"absent type", contractQuery{TypeID: 35}, nil},
+		{"region", contractQuery{RegionID: 2}, []int32{3}},
+		{"type and region", contractQuery{TypeID: 34, RegionID: 2}, nil},
+		{"location", contractQuery{TypeID: 34, LocationID: 101}, []int32{2}},
+		{"contract type", contractQuery{ContractType: "item_exchange"}, []int32{1, 3}},
+		{"min price", contractQuery{MinPrice: 150}, []int32{2, 3}},
+		{"max price", contractQuery{TypeID: 999, MaxPrice: 250}, []int32{2}},
+		{"min quantity", contractQuery{TypeID: 34, MinQuantity: 100}, []int32{1}},
+		{"blueprint copy", contractQuery{TypeID: 999, BlueprintCopy: &bpc}, []int32{2}},
+		{"blueprint original", contractQuery{TypeID: 999, BlueprintCopy: &bpo}, []int32{3}},
+		{"min me", contractQuery{TypeID: 999, MinME: 9}, []int32{2}},
+		{"min te", contractQuery{TypeID: 999, MinTE: 16}, []int32{2, 3}},
+		{"min runs", contractQuery{TypeID: 999, MinRuns: 1}, []int32{2}},
+		{"scoped region", contractQuery{TypeID: 999, scope: newScope(&auth.Key{Regions: []int64{2}})}, []int32{3}},
+		{"scoped location", contractQuery{scope: newScope(&auth.Key{Locations: []int64{100}})}, []int32{1}},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if test.query.Limit == 0 {
+					test.query.Limit = defaultSearchLimit
+				}
+				var ids []int32
+				for _, c := range s.findContracts(test.query) {
+					ids = append(ids, c.Contract.ContractId)
+				}
+				assert.ElementsMatch(t, test.ids, ids)
+			},
+		)
+	}
+
+	assert.Len(t, s.findContracts(contractQuery{Limit: 2}), 2)
+	assert.Len(t, s.findContracts(contractQuery{TypeID: 34, Limit: 1}), 1)
+}
@@ -0,0 +1,212 @@
+package marketwatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/contorno/eve-marketwatch/logging"
+)
+
+const defaultSearchLimit = 1000
+
+// contractQuery filters for the contract search
+type contractQuery struct {
+	TypeID        int32
+	MinPrice      float64
+	MaxPrice      float64
+	RegionID      int64
+	LocationID    int64
+	ContractType  string
+	BlueprintCopy *bool
+	MinME         int32
+	MinTE         int32
+	MinRuns       int32
+	MinQuantity   int32
+	Limit         int
+
+	// regions and locations of the key searching, nil for all
+	scope *scope
+}
+
+func parseContractQuery(q url.Values) (contractQuery, error) {
+	query := contractQuery{
+		ContractType: q.Get("type"),
+		Limit:        defaultSearchLimit,
+	}
+	var err error
+
+	if query.TypeID, err = queryInt32(q, "type_id"); err != nil {
+		return query, err
+	}
+	if query.MinPrice, err = queryFloat(q, "min_price"); err != nil {
+		return query, err
+	}
+	if query.MaxPrice, err = queryFloat(q, "max_price"); err != nil {
+		return query, err
+	}
+	if query.RegionID, err = queryInt64(q, "region_id"); err != nil {
+		return query, err
+	}
+	if query.LocationID, err = queryInt64(q, "location_id"); err != nil {
+		return query, err
+	}
+	if query.MinME, err = queryInt32(q, "min_me"); err != nil {
+		return query, err
+	}
+	if query.MinTE, err = queryInt32(q, "min_te"); err != nil {
+		return query, err
+	}
+	if query.MinRuns, err = queryInt32(q, "min_runs"); err != nil {
+		return query, err
+	}
+	if query.MinQuantity, err = queryInt32(q, "min_quantity"); err != nil {
+		return query, err
+	}
+	if v := q.Get("blueprint_copy"); v != "" {
+		bpc, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, err
+		}
+		query.BlueprintCopy = &bpc
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, err
+		}
+		if limit > 0 && limit < defaultSearchLimit {
+			query.Limit = limit
+		}
+	}
+
+	return query, nil
+}
+
+// matchItem checks an indexed item against the item filters
+func (q contractQuery) matchItem(item indexedItem) bool {
+	if q.RegionID != 0 && item.RegionID != q.RegionID {
+		return false
+	}
+	if !q.scope.region(item.RegionID) {
+		return false
+	}
+	if q.BlueprintCopy != nil && item.IsBlueprintCopy != *q.BlueprintCopy {
+		return false
+	}
+	return item.MaterialEfficiency >= q.MinME &&
+		item.TimeEfficiency >= q.MinTE &&
+		item.Runs >= q.MinRuns &&
+		item.Quantity >= q.MinQuantity
+}
+
+// matchContract checks a contract against the contract filters
+func (q contractQuery) matchContract(c FullContract) bool {
+	if q.ContractType != "" && c.Contract.Type_ != q.ContractType {
+		return false
+	}
+	if q.LocationID != 0 && c.Contract.StartLocationId != q.LocationID {
+		return false
+	}
+	if !q.scope.location(c.Contract.StartLocationId) {
+		return false
+	}
+	if q.MinPrice != 0 && c.Contract.Price < q.MinPrice {
+		return false
+	}
+	if q.MaxPrice != 0 && c.Contract.Price > q.MaxPrice {
+		return false
+	}
+	return true
+}
+
+// findContracts runs a contract query against the stores
+func (s *MarketWatch) findContracts(q contractQuery) []FullContract {
+	result := []FullContract{}
+
+	// Use the index when looking for a type
+	if q.TypeID != 0 {
+		for contractID, regionID := range s.contractIndex.find(q.TypeID, q.matchItem) {
+			sMap := s.getContractStore(regionID)
+			if sMap == nil {
+				continue
+			}
+			v, ok := sMap.Load(contractID)
+			if !ok {
+				continue
+			}
+			c := v.(Contract).Contract
+			if q.matchContract(c) {
+				result = append(result, c)
+				if len(result) >= q.Limit {
+					break
+				}
+			}
+		}
+		return result
+	}
+
+	// Otherwise scan the regions
+	s.cmutex.RLock()
+	defer s.cmutex.RUnlock()
+	for regionID, r := range s.contracts {
+		if (q.RegionID != 0 && regionID != q.RegionID) || !q.scope.region(regionID) {
+			continue
+		}
+		r.Range(
+			func(k, v interface{}) bool {
+				c := v.(Contract).Contract
+				if q.matchContract(c) {
+					result = append(result, c)
+				}
+				return len(result) < q.Limit
+			},
+		)
+		if len(result) >= q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// searchContracts handles contract search requests
+func (s *MarketWatch) searchContracts(w http.ResponseWriter, r *http.Request) {
+	q, err := parseContractQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.scope = scopeOf(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(s.findContracts(q))
+	if err != nil {
+		s.log.Error("writing contract search results", logging.Error, err)
+	}
+}
+
+func queryInt32(q url.Values, key string) (int32, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 32)
+	return int32(i), err
+}
+
+func queryInt64(q url.Values, key string) (int64, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+func queryFloat(q url.Values, key string) (float64, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
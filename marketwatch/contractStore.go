@@ -3,8 +3,6 @@ package marketwatch
 import (
 	"sync"
 	"time"
-
-	"github.com/contorno/goesi/esi"
 )
 
 // Contract wrapper to find last touch time.
@@ -14,27 +12,6 @@ type Contract struct {
 	Contract FullContract
 }
 
-// FullContract adds all three esi returns together
-type FullContract struct {
-	Contract esi.GetContractsPublicRegionId200Ok          `json:"contract"`
-	Items    []esi.GetContractsPublicItemsContractId200Ok `json:"items,omitempty"`
-	Bids     []esi.GetContractsPublicBidsContractId200Ok  `json:"bids,omitempty"`
-}
-
-// ContractChange Details of what changed on an contract
-// Really only price and bids can change
-type ContractChange struct {
-	ContractId  int32                                       `json:"contract_id"`
-	LocationId  int64                                       `json:"location_id"`
-	Expired     bool                                        `json:"expired,omitempty"`
-	DateExpired time.Time                                   `json:"date_expired,omitempty"`
-	Changed     bool                                        `json:"-"`
-	Bids        []esi.GetContractsPublicBidsContractId200Ok `json:"bids,omitempty"`
-	Price       float64                                     `json:"price,omitempty"`
-	Type_       string                                      `json:"type,omitempty"`
-	TimeChanged time.Time                                   `json:"time_changed,omitempty"`
-}
-
 // storeContract returns changes or true if the item is new
 func (s *MarketWatch) storeContract(locationID int64, c Contract) (ContractChange, bool) {
 	sMap := s.getContractStore(locationID)
@@ -48,58 +25,148 @@ func (s *MarketWatch) storeContract(locationID int64, c Contract) (ContractChang
 	if loaded {
 		contract := v.(Contract)
 		if len(contract.Contract.Bids) != len(c.Contract.Bids) {
-			change.Price = contract.Contract.Contract.Price
-			change.Bids = contract.Contract.Bids
-			change.Type_ = contract.Contract.Contract.Type_
-			change.DateExpired = contract.Contract.Contract.DateExpired
+			change.Price = c.Contract.Contract.Price
+			change.Bids = c.Contract.Bids
+			change.Type_ = c.Contract.Contract.Type_
+			change.DateExpired = c.Contract.Contract.DateExpired
 			change.Changed = true
 		}
-		sMap.Store(contract.Contract.Contract.ContractId, contract)
+		sMap.Store(c.Contract.Contract.ContractId, c)
+		s.contractIndex.add(locationID, c.Contract)
 		return change, false
 	}
+	s.contractIndex.add(locationID, c.Contract)
 	return change, true
 }
 
-func (s *MarketWatch) expireContracts(locationID int64, t time.Time) []ContractChange {
+// contractEvidence of what became of a contract that left the public list
+type contractEvidence int
+
+const (
+	// nothing is known
+	noEvidence contractEvidence = iota
+	// ESI still has it as expired or recently accepted
+	acceptedEvidence
+)
+
+// Contracts looked up per cycle for evidence of why they left, as deleted
+// ones count against the ESI error limit
+const maxContractProbes = 100
+
+// expireContracts not seen since t, with the reason they left. Probe, when
+// given, looks up the contracts that left early for evidence of why.
+func (s *MarketWatch) expireContracts(
+	locationID int64, t time.Time, probe func(FullContract) contractEvidence,
+) []ContractChange {
 	sMap := s.getContractStore(locationID)
-	var changes []ContractChange
+	now := time.Now()
 
 	// Find any expired contracts
+	var gone []FullContract
 	sMap.Range(
 		func(k, v interface{}) bool {
 			o := v.(Contract)
 			if t.After(o.Touched) {
-
-				expired := false
-				if o.Contract.Contract.DateExpired.Before(time.Now()) {
-					expired = true
-				}
-				changes = append(
-					changes, ContractChange{
-						ContractId:  o.Contract.Contract.ContractId,
-						LocationId:  o.Contract.Contract.StartLocationId,
-						Price:       o.Contract.Contract.Price,
-						Bids:        o.Contract.Bids,
-						Type_:       o.Contract.Contract.Type_,
-						DateExpired: o.Contract.Contract.DateExpired,
-						Changed:     true,
-						Expired:     expired,
-						TimeChanged: time.Now().UTC(), // We know this was within 30 minutes of this time
-					},
-				)
+				gone = append(gone, o.Contract)
 			}
 			return true
 		},
 	)
+	evidence := s.probeContracts(gone, now, probe)
+
+	changes := make([]ContractChange, 0, len(gone))
+	for i, c := range gone {
+		reason := deletionReason(c, evidence[i], now)
+		changes = append(
+			changes, ContractChange{
+				ContractId:  c.Contract.ContractId,
+				LocationId:  c.Contract.StartLocationId,
+				Price:       c.Contract.Price,
+				Bids:        c.Bids,
+				Items:       c.Items,
+				Type_:       c.Contract.Type_,
+				DateExpired: c.Contract.DateExpired,
+				Changed:     true,
+				Expired:     reason == ContractExpired,
+				Reason:      reason,
+				TimeChanged: time.Now().UTC(), // We know this was within 30 minutes of this time
+			},
+		)
+	}
 
 	// Delete them out of the map
 	for _, c := range changes {
 		sMap.Delete(c.ContractId)
+		s.contractIndex.remove(c.ContractId)
 	}
 
 	return changes
 }
 
+// probeContracts that left before their expiry date without bids to tell
+// why, up to maxContractProbes of them
+func (s *MarketWatch) probeContracts(
+	gone []FullContract, now time.Time, probe func(FullContract) contractEvidence,
+) []contractEvidence {
+	evidence := make([]contractEvidence, len(gone))
+	if probe == nil {
+		return evidence
+	}
+
+	wg := sync.WaitGroup{}
+	workers := make(chan struct{}, 5)
+	probes := 0
+	for i, c := range gone {
+		if !needsEvidence(c, now) {
+			continue
+		}
+		if probes++; probes > maxContractProbes {
+			break
+		}
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, c FullContract) {
+			defer wg.Done()
+			evidence[i] = probe(c)
+			<-workers
+		}(i, c)
+	}
+	wg.Wait()
+	return evidence
+}
+
+// needsEvidence of why a contract left, when its bids and expiry do not say
+func needsEvidence(c FullContract, now time.Time) bool {
+	if c.Contract.Type_ == "auction" && len(c.Bids) > 0 {
+		return false
+	}
+	return !contractExpired(c, now)
+}
+
+func contractExpired(c FullContract, now time.Time) bool {
+	return !c.Contract.DateExpired.IsZero() && !c.Contract.DateExpired.After(now)
+}
+
+// deletionReason guesses why a contract is no longer listed.
+// ESI only lists outstanding public contracts, so anything that vanishes
+// before its expiry date was accepted or deleted by the issuer, which only
+// the evidence tells apart.
+func deletionReason(c FullContract, evidence contractEvidence, now time.Time) string {
+	switch {
+	case c.Contract.Type_ == "auction" && len(c.Bids) > 0:
+		// Any standing bid means the auction was won or bought out.
+		return ContractAccepted
+	case contractExpired(c, now):
+		return ContractExpired
+	case evidence != acceptedEvidence:
+		return ContractRemoved
+	case c.Contract.Type_ == "courier":
+		// Accepted couriers are in progress until delivered
+		return ContractInProgress
+	}
+	return ContractAccepted
+}
+
 // getContractStore for a location
 func (s *MarketWatch) getContractStore(locationID int64) *sync.Map {
 	s.cmutex.RLock()
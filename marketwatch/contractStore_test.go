@@ -0,0 +1,96 @@
+package marketwatch
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contorno/goesi/esi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletionReason(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	bids := []esi.GetContractsPublicBidsContractId200Ok{{Amount: 10}}
+
+	contract := func(kind string, expires time.Time, bids []esi.GetContractsPublicBidsContractId200Ok) FullContract {
+		return FullContract{
+			Contract: esi.GetContractsPublicRegionId200Ok{Type_: kind, DateExpired: expires},
+			Bids:     bids,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		contract FullContract
+		evidence contractEvidence
+		reason   string
+	}{
+		{"auction with bids, unexpired", contract("auction", future, bids), noEvidence, ContractAccepted},
+		{"auction with bids, expired", contract("auction", past, bids), noEvidence, ContractAccepted},
+		{"auction without bids, unexpired", contract("auction", future, nil), noEvidence, ContractRemoved},
+		{"auction without bids, bought out", contract("auction", future, nil), acceptedEvidence, ContractAccepted},
+		{"auction without bids, expired", contract("auction", past, nil), noEvidence, ContractExpired},
+		{"courier, unexpired", contract("courier", future, nil), noEvidence, ContractRemoved},
+		{"courier, accepted", contract("courier", future, nil), acceptedEvidence, ContractInProgress},
+		{"courier, expired", contract("courier", past, nil), acceptedEvidence, ContractExpired},
+		{"item exchange, unexpired", contract("item_exchange", future, nil), noEvidence, ContractRemoved},
+		{"item exchange, accepted", contract("item_exchange", future, nil), acceptedEvidence, ContractAccepted},
+		{"item exchange, expired", contract("item_exchange", past, nil), noEvidence, ContractExpired},
+		{"unknown type, unexpired", contract("unknown", future, nil), noEvidence, ContractRemoved},
+		{"unknown type, expired", contract("unknown", past, nil), noEvidence, ContractExpired},
+		{"no expiry date", contract("item_exchange", time.Time{}, nil), noEvidence, ContractRemoved},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				assert.Equal(t, test.reason, deletionReason(test.contract, test.evidence, now))
+			},
+		)
+	}
+}
+
+func TestProbeContracts(t *testing.T) {
+	now := time.Now()
+	gone := []FullContract{
+		{Contract: esi.GetContractsPublicRegionId200Ok{ContractId: 1, Type_: "courier", DateExpired: now.Add(time.Hour)}},
+		{Contract: esi.GetContractsPublicRegionId200Ok{ContractId: 2, Type_: "courier", DateExpired: now.Add(-time.Hour)}},
+		{
+			Contract: esi.GetContractsPublicRegionId200Ok{ContractId: 3, Type_: "auction", DateExpired: now.Add(time.Hour)},
+			Bids:     []esi.GetContractsPublicBidsContractId200Ok{{Amount: 10}},
+		},
+		{Contract: esi.GetContractsPublicRegionId200Ok{ContractId: 4, Type_: "item_exchange", DateExpired: now.Add(time.Hour)}},
+	}
+
+	// Only what the expiry and bids do not tell is looked up
+	var probed int32
+	s := &MarketWatch{}
+	evidence := s.probeContracts(
+		gone, now, func(c FullContract) contractEvidence {
+			atomic.AddInt32(&probed, 1)
+			if c.Contract.ContractId == 1 {
+				return acceptedEvidence
+			}
+			return noEvidence
+		},
+	)
+	assert.Equal(t, []contractEvidence{acceptedEvidence, noEvidence, noEvidence, noEvidence}, evidence)
+	assert.Equal(t, int32(2), probed)
+
+	// and no more than the cap
+	many := make([]FullContract, maxContractProbes+10)
+	for i := range many {
+		many[i] = gone[0]
+	}
+	probed = 0
+	s.probeContracts(
+		many, now, func(FullContract) contractEvidence {
+			atomic.AddInt32(&probed, 1)
+			return acceptedEvidence
+		},
+	)
+	assert.Equal(t, int32(maxContractProbes), probed)
+
+	assert.Equal(t, make([]contractEvidence, 4), s.probeContracts(gone, now, nil))
+}
@@ -3,13 +3,14 @@ package marketwatch
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
-	"log"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/goesi"
 	"github.com/contorno/goesi/esi"
 	"github.com/contorno/optional"
 	"github.com/getsentry/sentry-go"
@@ -26,10 +27,20 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 	// For totalization
 	wg := sync.WaitGroup{}
 
+	// Completed cycles, the version of the region's data
+	var cycle uint64
+
+	// Cycles failed in a row
+	var failures int
+
+	logger := logging.WithHub(s.log, localHub).With(logging.Channel, "contract", logging.RegionID, regionID)
+
 	// Loop forever
 	for {
 		start := time.Now()
 		numContracts := 0
+		status := newCycleStatus(int64(regionID), "contract", start)
+		cycleLog := logger.With(logging.CycleID, cycle+1)
 
 		// Return Channels
 		rchan := make(chan []esi.GetContractsPublicRegionId200Ok, 100000)
@@ -39,20 +50,24 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 			context.Background(), regionID, nil,
 		)
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			cycleLog.Error("fetching the first page", logging.Error, err)
+			failures++
+			time.Sleep(s.cycleFailed(status, failures, err))
 			continue
 		}
 		rchan <- contracts
 
 		// Figure out if there are more pages
 		pages, _ := getPages(res)
+		status.Pages = pages
+		status.Expires = goesi.CacheExpires(res)
 		duration := timeUntilCacheExpires(res)
 		if duration.Minutes() < 3 {
-			fmt.Printf("%d contract too close to window: waiting %s\n", regionID, duration.String())
+			s.cycleDelayed(status, duration)
 			time.Sleep(duration)
 			continue
 		}
+		s.health.pulled(status)
 
 		// Get the other pages concurrently
 		for pages > 1 {
@@ -65,6 +80,7 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 				)
 
 				defer wg.Done() // release when done
+				pageLog := logging.WithHub(cycleLog, localHub).With(logging.Page, page)
 
 				// Throttle down the requests to avoid bans.
 				sleepRandom(3, 0.5)
@@ -73,6 +89,7 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 					context.Background(), regionID, &esi.GetContractsPublicRegionIdOpts{Page: optional.NewInt32(page)},
 				)
 				if err != nil {
+					pageLog.Error("fetching a page", logging.Error, err)
 					echan <- err
 					return
 				}
@@ -80,7 +97,9 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 				// Are we too close to the end of the window?
 				duration = timeUntilCacheExpires(r)
 				if duration.Seconds() < 20 {
-					echan <- errors.New("contract too close to end of window")
+					err := errors.New("contract too close to end of window")
+					pageLog.Error("fetching a page", logging.Error, err)
+					echan <- err
 					return
 				}
 
@@ -96,15 +115,20 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 		close(rchan)
 		close(echan)
 
+		var pageErrors []error
 		for err := range echan {
-			sentry.CaptureException(err)
-			log.Println(err)
-			// Start over if any requests failed
+			pageErrors = append(pageErrors, err)
+		}
+		// Start over if any requests failed
+		if len(pageErrors) > 0 {
+			failures++
+			time.Sleep(s.cycleFailed(status, failures, pageErrors...))
 			continue
 		}
 
 		var changes []ContractChange
 		var newContracts []FullContract
+		var deals []FullContract
 		// Add all the contracts together
 		for o := range rchan {
 		Restart:
@@ -119,7 +143,7 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 				if o[i].Type_ == "item_exchange" || o[i].Type_ == "auction" {
 					err := s.getContractItems(&contract)
 					if err != nil {
-						sentry.CaptureException(err)
+						cycleLog.Error("fetching contract items", "contract_id", o[i].ContractId, logging.Error, err)
 						goto Restart
 					}
 				}
@@ -127,11 +151,19 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 				if o[i].Type_ == "auction" {
 					err := s.getContractBids(&contract)
 					if err != nil {
-						sentry.CaptureException(err)
+						cycleLog.Error("fetching contract bids", "contract_id", o[i].ContractId, logging.Error, err)
 						goto Restart
 					}
 				}
 
+				// The cycle is still pulling, however long it takes
+				if o[i].Type_ == "item_exchange" || o[i].Type_ == "auction" {
+					s.health.pulled(status)
+				}
+
+				s.prices.appraise(&contract.Contract)
+				s.enrichCourier(&contract.Contract)
+
 				change, isNew := s.storeContract(int64(regionID), contract)
 				numContracts++
 				if change.Changed && !isNew {
@@ -139,46 +171,85 @@ func (s *MarketWatch) contractWorker(regionID int32, localHub *sentry.Hub) {
 				}
 				if isNew {
 					newContracts = append(newContracts, contract.Contract)
+					if isDeal(contract.Contract, s.dealRatio) {
+						deals = append(deals, contract.Contract)
+					}
 				}
 			}
 		}
-		deletions := s.expireContracts(int64(regionID), start)
+		deletions := s.expireContracts(int64(regionID), start, s.contractEvidence)
+		cycle++
+		failures = 0
+		held := s.snapshotContracts(int64(regionID), cycle)
 
 		// Log metrics
+		region := strconv.FormatInt(int64(regionID), 10)
 		metricContractTimePull.With(
 			prometheus.Labels{
-				"locationID": strconv.FormatInt(int64(regionID), 10),
+				"locationID": region,
 			},
 		).Observe(float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond))
+		metricContracts.With(prometheus.Labels{"locationID": region}).Set(float64(held))
+		metricContractChanges.With(prometheus.Labels{"locationID": region, "kind": "addition"}).Add(float64(len(newContracts)))
+		metricContractChanges.With(prometheus.Labels{"locationID": region, "kind": "change"}).Add(float64(len(changes)))
+		metricContractChanges.With(prometheus.Labels{"locationID": region, "kind": "deletion"}).Add(float64(len(deletions)))
+
+		s.checkContracts(int64(regionID), "contractAddition", newContracts)
+		s.checkContractChanges(int64(regionID), "contractChange", changes)
+		s.checkContractChanges(int64(regionID), "contractDeletion", deletions)
 
 		if len(newContracts) > 0 {
 			s.broadcast.Broadcast(
-				"contract", Message{
-					Action:  "contractAddition",
-					Payload: newContracts,
-				},
+				"contract", enrichedMessage(
+					Message{Action: "contractAddition", Payload: newContracts, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichContracts(newContracts)
+					},
+				),
+			)
+		}
+
+		if len(deals) > 0 {
+			s.broadcast.Broadcast(
+				"deals", enrichedMessage(
+					Message{Action: "deal", Payload: deals, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichContracts(deals)
+					},
+				),
 			)
 		}
 
 		// Only bids really change.
 		if len(changes) > 0 {
 			s.broadcast.Broadcast(
-				"contract", Message{
-					Action:  "contractChange",
-					Payload: changes,
-				},
+				"contract", enrichedMessage(
+					Message{Action: "contractChange", Payload: changes, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichContractChanges(changes)
+					},
+				),
 			)
 		}
 
 		if len(deletions) > 0 {
 			s.broadcast.Broadcast(
-				"contract", Message{
-					Action:  "contractDeletion",
-					Payload: deletions,
-				},
+				"contract", enrichedMessage(
+					Message{Action: "contractDeletion", Payload: deletions, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichContractChanges(deletions)
+					},
+				),
 			)
 		}
 
+		status.Cycle = cycle
+		status.Additions = len(newContracts)
+		status.Changes = len(changes)
+		status.Deletions = len(deletions)
+		status.NextRun = time.Now().Add(duration)
+		s.cycleComplete(status)
+
 		// Sleep until the cache timer expires, plus a little.
 		time.Sleep(duration)
 	}
@@ -201,8 +272,7 @@ func (s *MarketWatch) getContractItems(contract *Contract) error {
 	defer func(Body io.ReadCloser) {
 		thisErr := Body.Close()
 		if thisErr != nil {
-			sentry.CaptureException(thisErr)
-			log.Println(thisErr)
+			s.log.Error("closing the response", logging.Error, thisErr)
 		}
 	}(res.Body)
 
@@ -234,8 +304,7 @@ func (s *MarketWatch) getContractItems(contract *Contract) error {
 			defer func(Body io.ReadCloser) {
 				thisErr := Body.Close()
 				if thisErr != nil {
-					sentry.CaptureException(thisErr)
-					log.Println(thisErr)
+					logging.WithHub(s.log, localHub).Error("closing the response", logging.Page, page, logging.Error, thisErr)
 				}
 			}(itemsRes.Body)
 
@@ -266,6 +335,23 @@ func (s *MarketWatch) getContractItems(contract *Contract) error {
 	return nil
 }
 
+// contractEvidence of why a contract left the public list. ESI answers for
+// the items of one that expired or was recently accepted with no content,
+// and no longer knows one that was deleted.
+func (s *MarketWatch) contractEvidence(c FullContract) contractEvidence {
+	// Throttle down the requests to avoid bans.
+	sleepRandom(3, 0.5)
+
+	// The body is read and closed by the client, and is empty when it matters
+	_, res, _ := s.esi.ESI.ContractsApi.GetContractsPublicItemsContractId(
+		context.Background(), c.Contract.ContractId, nil,
+	)
+	if res != nil && res.StatusCode == http.StatusNoContent {
+		return acceptedEvidence
+	}
+	return noEvidence
+}
+
 // getContractBids for a single contract. Must be prefilled with the contract.
 func (s *MarketWatch) getContractBids(contract *Contract) error {
 	wg := sync.WaitGroup{}
@@ -281,8 +367,7 @@ func (s *MarketWatch) getContractBids(contract *Contract) error {
 		context.Background(), contract.Contract.Contract.ContractId, nil,
 	)
 	if err != nil {
-		sentry.CaptureException(err)
-		log.Println(err)
+		s.log.Error("fetching contract bids", "contract_id", contract.Contract.Contract.ContractId, logging.Error, err)
 	}
 	rchan <- bids
 	pages, _ := getPages(res)
@@ -309,8 +394,7 @@ func (s *MarketWatch) getContractBids(contract *Contract) error {
 			defer func(Body io.ReadCloser) {
 				thisErr := Body.Close()
 				if thisErr != nil {
-					sentry.CaptureException(thisErr)
-					log.Println(thisErr)
+					logging.WithHub(s.log, localHub).Error("closing the response", logging.Page, page, logging.Error, thisErr)
 				}
 			}(bidsRes.Body)
 
@@ -352,10 +436,30 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(10, 1.6, 20),
 		}, []string{"locationID"},
 	)
+
+	metricContracts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "contract",
+			Name:      "contracts",
+			Help:      "Contracts held per region after its last cycle.",
+		}, []string{"locationID"},
+	)
+
+	metricContractChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "contract",
+			Name:      "changes",
+			Help:      "Contracts added, changed and deleted per region.",
+		}, []string{"locationID", "kind"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(
 		metricContractTimePull,
+		metricContracts,
+		metricContractChanges,
 	)
 }
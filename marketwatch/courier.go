@@ -0,0 +1,290 @@
+package marketwatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/sde"
+)
+
+// routeCache remembers routes between systems
+type routeCache struct {
+	static *sde.Store
+	routes *sync.Map // [2]int32 -> *CourierRoute
+	mutex  sync.RWMutex
+}
+
+func newRouteCache(static *sde.Store) *routeCache {
+	r := &routeCache{
+		static: static,
+		routes: &sync.Map{},
+	}
+	// Forget the routes when the stargates change
+	if static != nil {
+		static.OnReload(
+			func(*sde.Data) {
+				r.mutex.Lock()
+				defer r.mutex.Unlock()
+				r.routes = &sync.Map{}
+			},
+		)
+	}
+	return r
+}
+
+func (r *routeCache) cache() *sync.Map {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.routes
+}
+
+// route between two locations. Returns nil when either end is unknown,
+// such as player structures, or there is no stargate route.
+func (r *routeCache) route(start, end int64) *CourierRoute {
+	universe := r.static.Data()
+	if universe == nil {
+		return nil
+	}
+	routes := r.cache()
+
+	from, ok := universe.SystemOf(start)
+	if !ok {
+		return nil
+	}
+	to, ok := universe.SystemOf(end)
+	if !ok {
+		return nil
+	}
+
+	key := [2]int32{from, to}
+	if v, ok := routes.Load(key); ok {
+		return v.(*CourierRoute)
+	}
+
+	systems := universe.Route(from, to)
+	if systems == nil {
+		routes.Store(key, (*CourierRoute)(nil))
+		return nil
+	}
+	route := &CourierRoute{
+		StartSystemID: from,
+		EndSystemID:   to,
+		Jumps:         len(systems) - 1,
+	}
+	for _, id := range systems {
+		switch sde.SecurityClass(universe.Systems[id].Security) {
+		case sde.HighSec:
+			route.HighSec++
+		case sde.LowSec:
+			route.LowSec++
+		default:
+			route.NullSec++
+		}
+	}
+	routes.Store(key, route)
+	return route
+}
+
+// enrichCourier adds the route and hauling ratios to a courier contract
+func (s *MarketWatch) enrichCourier(c *FullContract) {
+	if c.Contract.Type_ != "courier" {
+		return
+	}
+
+	info := &CourierInfo{
+		Route: s.routes.route(c.Contract.StartLocationId, c.Contract.EndLocationId),
+	}
+	if c.Contract.Volume > 0 {
+		info.IskPerM3 = c.Contract.Reward / c.Contract.Volume
+	}
+	if c.Contract.Reward > 0 {
+		info.CollateralRatio = c.Contract.Collateral / c.Contract.Reward
+	}
+	if info.Route != nil {
+		// Same system deliveries still take a trip
+		jumps := info.Route.Jumps
+		if jumps < 1 {
+			jumps = 1
+		}
+		info.IskPerJump = c.Contract.Reward / float64(jumps)
+	}
+	c.Courier = info
+}
+
+// courierQuery filters for the courier view
+type courierQuery struct {
+	RegionID           int64
+	StartSystemID      int32
+	EndSystemID        int32
+	MinReward          float64
+	MaxCollateral      float64
+	MaxVolume          float64
+	MaxJumps           int32
+	MaxLowSec          int32
+	MaxNullSec         int32
+	MinIskPerJump      float64
+	MinIskPerM3        float64
+	MaxCollateralRatio float64
+	Limit              int
+
+	// regions and locations of the key searching, nil for all
+	scope *scope
+}
+
+func parseCourierQuery(q url.Values) (courierQuery, error) {
+	query := courierQuery{
+		Limit:      defaultSearchLimit,
+		MaxLowSec:  -1,
+		MaxNullSec: -1,
+	}
+	var err error
+
+	if query.RegionID, err = queryInt64(q, "region_id"); err != nil {
+		return query, err
+	}
+	if query.StartSystemID, err = queryInt32(q, "start_system_id"); err != nil {
+		return query, err
+	}
+	if query.EndSystemID, err = queryInt32(q, "end_system_id"); err != nil {
+		return query, err
+	}
+	if query.MinReward, err = queryFloat(q, "min_reward"); err != nil {
+		return query, err
+	}
+	if query.MaxCollateral, err = queryFloat(q, "max_collateral"); err != nil {
+		return query, err
+	}
+	if query.MaxVolume, err = queryFloat(q, "max_volume"); err != nil {
+		return query, err
+	}
+	if query.MaxJumps, err = queryInt32(q, "max_jumps"); err != nil {
+		return query, err
+	}
+	if query.MinIskPerJump, err = queryFloat(q, "min_isk_per_jump"); err != nil {
+		return query, err
+	}
+	if query.MinIskPerM3, err = queryFloat(q, "min_isk_per_m3"); err != nil {
+		return query, err
+	}
+	if query.MaxCollateralRatio, err = queryFloat(q, "max_collateral_ratio"); err != nil {
+		return query, err
+	}
+	if q.Get("max_low_sec") != "" {
+		if query.MaxLowSec, err = queryInt32(q, "max_low_sec"); err != nil {
+			return query, err
+		}
+	}
+	if q.Get("max_null_sec") != "" {
+		if query.MaxNullSec, err = queryInt32(q, "max_null_sec"); err != nil {
+			return query, err
+		}
+	}
+	if q.Get("high_sec") != "" {
+		query.MaxLowSec = 0
+		query.MaxNullSec = 0
+	}
+	if limit, err := queryInt32(q, "limit"); err != nil {
+		return query, err
+	} else if limit > 0 && limit < defaultSearchLimit {
+		query.Limit = int(limit)
+	}
+
+	return query, nil
+}
+
+// match a courier contract against the filters
+func (q courierQuery) match(c FullContract) bool {
+	if c.Contract.Type_ != "courier" || c.Courier == nil {
+		return false
+	}
+	if !q.scope.location(c.Contract.StartLocationId) {
+		return false
+	}
+	info := c.Courier
+	if q.MinReward != 0 && c.Contract.Reward < q.MinReward {
+		return false
+	}
+	if q.MaxCollateral != 0 && c.Contract.Collateral > q.MaxCollateral {
+		return false
+	}
+	if q.MaxVolume != 0 && c.Contract.Volume > q.MaxVolume {
+		return false
+	}
+	if q.MinIskPerM3 != 0 && info.IskPerM3 < q.MinIskPerM3 {
+		return false
+	}
+	if q.MaxCollateralRatio != 0 && info.CollateralRatio > q.MaxCollateralRatio {
+		return false
+	}
+
+	// Anything past here needs a known route
+	if q.StartSystemID == 0 && q.EndSystemID == 0 && q.MaxJumps == 0 &&
+		q.MaxLowSec < 0 && q.MaxNullSec < 0 && q.MinIskPerJump == 0 {
+		return true
+	}
+	route := info.Route
+	if route == nil {
+		return false
+	}
+	if q.StartSystemID != 0 && route.StartSystemID != q.StartSystemID {
+		return false
+	}
+	if q.EndSystemID != 0 && route.EndSystemID != q.EndSystemID {
+		return false
+	}
+	if q.MaxJumps != 0 && route.Jumps > int(q.MaxJumps) {
+		return false
+	}
+	if q.MaxLowSec >= 0 && route.LowSec > int(q.MaxLowSec) {
+		return false
+	}
+	if q.MaxNullSec >= 0 && route.NullSec > int(q.MaxNullSec) {
+		return false
+	}
+	return q.MinIskPerJump == 0 || info.IskPerJump >= q.MinIskPerJump
+}
+
+// findCouriers runs a courier query against the stores
+func (s *MarketWatch) findCouriers(q courierQuery) []FullContract {
+	result := []FullContract{}
+
+	s.cmutex.RLock()
+	defer s.cmutex.RUnlock()
+	for regionID, r := range s.contracts {
+		if (q.RegionID != 0 && regionID != q.RegionID) || !q.scope.region(regionID) {
+			continue
+		}
+		r.Range(
+			func(k, v interface{}) bool {
+				c := v.(Contract).Contract
+				if q.match(c) {
+					result = append(result, c)
+				}
+				return len(result) < q.Limit
+			},
+		)
+		if len(result) >= q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// searchCouriers handles courier view requests
+func (s *MarketWatch) searchCouriers(w http.ResponseWriter, r *http.Request) {
+	q, err := parseCourierQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.scope = scopeOf(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(s.findCouriers(q))
+	if err != nil {
+		s.log.Error("writing courier search results", logging.Error, err)
+	}
+}
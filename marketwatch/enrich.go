@@ -0,0 +1,153 @@
+package marketwatch
+
+import (
+	"github.com/contorno/eve-marketwatch/sde"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/contorno/goesi/esi"
+)
+
+// Option for clients that want names with their payloads
+const enrichOption = "enrich"
+
+// Enrichment resolves the IDs of a payload
+type Enrichment struct {
+	TypeName      string  `json:"type_name,omitempty"`
+	SolarSystemID int32   `json:"solar_system_id,omitempty"`
+	Security      float64 `json:"security_status"`
+	LocationName  string  `json:"location_name,omitempty"`
+	RegionID      int32   `json:"region_id,omitempty"`
+	RegionName    string  `json:"region_name,omitempty"`
+}
+
+// esiOrder drops the easyjson methods of the ESI order so that its fields
+// flatten into EnrichedOrder in every encoding
+type esiOrder esi.GetMarketsRegionIdOrders200Ok
+
+// EnrichedOrder is an ESI order with its enrichment in the same object
+type EnrichedOrder struct {
+	esiOrder
+	Enrichment
+}
+
+// EnrichedOrderChange is an order change with its enrichment
+type EnrichedOrderChange struct {
+	OrderChange
+	Enrichment
+}
+
+// EnrichedContract is a contract with its start location, the end location
+// of couriers and the names of its items
+type EnrichedContract struct {
+	FullContract
+	Enrichment
+	EndLocation *Enrichment      `json:"end_location,omitempty"`
+	TypeNames   map[int32]string `json:"type_names,omitempty"`
+}
+
+// EnrichedContractChange is a contract change with its enrichment
+type EnrichedContractChange struct {
+	ContractChange
+	Enrichment
+	TypeNames map[int32]string `json:"type_names,omitempty"`
+}
+
+// enrichedMessage sends the enriched payload to clients that asked for it
+func enrichedMessage(m Message, enrich func() interface{}) *wsbroadcast.OptionalMessage {
+	return wsbroadcast.NewOptionalMessage(
+		enrichOption,
+		m,
+		func() interface{} {
+			enriched := m
+			enriched.Payload = enrich()
+			return enriched
+		},
+	)
+}
+
+// enrichment of a location and optional type
+func (s *MarketWatch) enrichment(typeID int32, locationID int64, systemHint int32) Enrichment {
+	loc := s.universe.location(locationID, systemHint)
+	e := enrichmentOf(loc)
+	if typeID != 0 {
+		e.TypeName = s.universe.typeName(typeID)
+	}
+	return e
+}
+
+func enrichmentOf(loc sde.Location) Enrichment {
+	name := loc.StationName
+	if name == "" && loc.StationID == 0 {
+		name = loc.SolarSystemName
+	}
+	return Enrichment{
+		SolarSystemID: loc.SolarSystemID,
+		Security:      loc.Security,
+		LocationName:  name,
+		RegionID:      loc.RegionID,
+		RegionName:    loc.RegionName,
+	}
+}
+
+func (s *MarketWatch) enrichOrders(orders []esi.GetMarketsRegionIdOrders200Ok) []EnrichedOrder {
+	enriched := make([]EnrichedOrder, len(orders))
+	for i, o := range orders {
+		enriched[i] = EnrichedOrder{
+			esiOrder:   esiOrder(o),
+			Enrichment: s.enrichment(o.TypeId, o.LocationId, o.SystemId),
+		}
+	}
+	return enriched
+}
+
+func (s *MarketWatch) enrichOrderChanges(changes []OrderChange) []EnrichedOrderChange {
+	enriched := make([]EnrichedOrderChange, len(changes))
+	for i, c := range changes {
+		enriched[i] = EnrichedOrderChange{
+			OrderChange: c,
+			Enrichment:  s.enrichment(c.TypeID, c.LocationId, 0),
+		}
+	}
+	return enriched
+}
+
+func (s *MarketWatch) enrichContracts(contracts []FullContract) []EnrichedContract {
+	enriched := make([]EnrichedContract, len(contracts))
+	for i, c := range contracts {
+		enriched[i] = EnrichedContract{
+			FullContract: c,
+			Enrichment:   s.enrichment(0, c.Contract.StartLocationId, 0),
+			TypeNames:    s.typeNames(c.Items),
+		}
+		if c.Contract.Type_ == "courier" {
+			end := s.enrichment(0, c.Contract.EndLocationId, 0)
+			enriched[i].EndLocation = &end
+		}
+	}
+	return enriched
+}
+
+func (s *MarketWatch) enrichContractChanges(changes []ContractChange) []EnrichedContractChange {
+	enriched := make([]EnrichedContractChange, len(changes))
+	for i, c := range changes {
+		enriched[i] = EnrichedContractChange{
+			ContractChange: c,
+			Enrichment:     s.enrichment(0, c.LocationId, 0),
+			TypeNames:      s.typeNames(c.Items),
+		}
+	}
+	return enriched
+}
+
+// typeNames of contract items
+func (s *MarketWatch) typeNames(items []esi.GetContractsPublicItemsContractId200Ok) map[int32]string {
+	if len(items) == 0 {
+		return nil
+	}
+	names := make(map[int32]string)
+	for _, item := range items {
+		if _, ok := names[item.TypeId]; !ok {
+			names[item.TypeId] = s.universe.typeName(item.TypeId)
+		}
+	}
+	return names
+}
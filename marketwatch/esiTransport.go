@@ -1,18 +1,15 @@
 package marketwatch
 
 import (
-	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"regexp"
 	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
 )
 
-var debugRaw = os.Getenv("DEBUG")
 var apiTransportLimiter chan bool
 var urlFilterRe *regexp.Regexp
 
@@ -24,33 +21,25 @@ func init() {
 
 type APITransport struct {
 	next *http.Transport
+	log  *slog.Logger
 }
 
-func logRoundTrip(req *http.Request, res *http.Response, reset int64, remain int64) {
-	fmtStr := "\n\tRequest: %s\n\t\tQuery parameters: %v\n\t\tHeaders: %v\n\t\tStatus code: %d.\n\t\tStatus: %s." +
-		"\n\t\tSeconds until error limit reset: %d\n\t\tError limit remaining: %d\n"
-	fmtArgs := []any{
-		req.Method + " " + req.URL.Path,
-		req.URL.Query(),
-		req.Header,
-		res.StatusCode,
-		res.Status,
-		reset,
-		remain,
+// logRoundTrip logs failed requests, and the others at the debug level
+func (t *APITransport) logRoundTrip(req *http.Request, res *http.Response, tries int, reset int64, remain int64) {
+	level := slog.LevelDebug
+	if res.StatusCode >= 400 {
+		level = slog.LevelWarn
 	}
-
-	var debug bool
-	if debugRaw == "true" {
-		debug = true
-	} else {
-		debug = false
-	}
-
-	if !debug && (res.StatusCode >= 200 && res.StatusCode < 400) {
-		return
-	}
-
-	log.Printf(fmtStr, fmtArgs...)
+	t.log.Log(
+		req.Context(), level, "esi request",
+		"method", req.Method,
+		"path", req.URL.Path,
+		"query", req.URL.RawQuery,
+		"status", res.StatusCode,
+		"try", tries,
+		"error_limit_reset", reset,
+		"error_limit_remain", remain,
+	)
 }
 
 // RoundTrip wraps http.DefaultTransport.RoundTrip to provide stats and handle error rates.
@@ -98,11 +87,10 @@ func (t *APITransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 			if res.StatusCode >= 400 {
 				metricAPIErrors.Inc()
-				logRoundTrip(req, res, reset, remain)
+				t.logRoundTrip(req, res, tries, reset, remain)
 
 				// do not retry 4xx errors
 				if res.StatusCode >= 400 && res.StatusCode < 500 {
-					fmt.Printf("%d error for %s %s. Skipping retry.\n", res.StatusCode, req.Method, req.URL.Path)
 					return res, triperr
 				}
 
@@ -114,13 +102,13 @@ func (t *APITransport) RoundTrip(req *http.Request) (*http.Response, error) {
 					time.Sleep(time.Second * time.Duration((tries*tries)+(4*tries)))
 				}
 			} else if res.StatusCode >= 200 && res.StatusCode < 400 {
-				logRoundTrip(req, res, reset, remain)
+				t.logRoundTrip(req, res, tries, reset, remain)
 				return res, triperr
 			}
 		}
 
 		if tries > 5 {
-			log.Printf("too many tries, aborting\n")
+			t.log.Warn("too many tries, aborting", "method", req.Method, "path", req.URL.Path, "try", tries)
 			return res, triperr
 		}
 	}
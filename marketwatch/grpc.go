@@ -0,0 +1,618 @@
+package marketwatch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/auth"
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/marketwatchpb"
+	"github.com/contorno/goesi/esi"
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer serves the broadcasts and stores over gRPC
+type grpcServer struct {
+	marketwatchpb.UnimplementedMarketWatchServer
+
+	mw *MarketWatch
+
+	mutex       sync.RWMutex
+	subscribers map[*grpcSubscriber]bool
+}
+
+type grpcSubscriber struct {
+	filter  *marketwatchpb.SubscribeRequest
+	key     *auth.Key // nil when access is open
+	scope   *scope
+	send    chan *grpcBroadcast
+	dropped chan struct{}
+}
+
+// grpcBroadcast is a broadcast queued for the subscribers. It is converted
+// to protobuf on their goroutines, once for all those without a scope.
+type grpcBroadcast struct {
+	channel string
+	msg     Message
+
+	once  sync.Once
+	event *marketwatchpb.Event
+}
+
+// shared conversion of the broadcast
+func (b *grpcBroadcast) shared() *marketwatchpb.Event {
+	b.once.Do(
+		func() {
+			b.event = toPbEvent(b.channel, b.msg)
+		},
+	)
+	return b.event
+}
+
+// eventFor a subscriber, nil when nothing of the broadcast is for it
+func (sub *grpcSubscriber) eventFor(b *grpcBroadcast) *marketwatchpb.Event {
+	event := b.shared()
+	if sub.scope != nil {
+		m, ok := sub.scope.filterMessage(b.msg).(Message)
+		if !ok {
+			return nil
+		}
+		event = toPbEvent(b.channel, m)
+	}
+	if event == nil {
+		return nil
+	}
+	return filterPbEvent(event, sub.filter)
+}
+
+func newGRPCServer(mw *MarketWatch) *grpcServer {
+	return &grpcServer{
+		mw:          mw,
+		subscribers: make(map[*grpcSubscriber]bool),
+	}
+}
+
+// serve gRPC on an address
+func (g *grpcServer) serve(addr string, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#serve-grpc")
+		},
+	)
+
+	logger := logging.WithHub(g.mw.log, localHub)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("listening for grpc", logging.Error, err)
+		os.Exit(1)
+	}
+	options := []grpc.ServerOption{grpc.MaxSendMsgSize(256 * 1024 * 1024)}
+	if g.mw.keys != nil {
+		options = append(
+			options,
+			grpc.UnaryInterceptor(g.authorizeUnary),
+			grpc.StreamInterceptor(g.authorizeStream),
+		)
+	}
+	server := grpc.NewServer(options...)
+	marketwatchpb.RegisterMarketWatchServer(server, g)
+	logger.Info("serving grpc", "addr", addr)
+
+	err = server.Serve(listener)
+	if err != nil {
+		logger.Error("serving grpc", logging.Error, err)
+		os.Exit(1)
+	}
+}
+
+// authorize a call with the keys of the websocket, given in the authorization
+// (Bearer) or x-api-key metadata
+func (g *grpcServer) authorize(ctx context.Context) (context.Context, func(), error) {
+	var secret string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("authorization"); len(v) > 0 && strings.HasPrefix(v[0], "Bearer ") {
+		secret = strings.TrimPrefix(v[0], "Bearer ")
+	} else if v := md.Get("x-api-key"); len(v) > 0 {
+		secret = v[0]
+	}
+
+	key, release, err := g.mw.keys.AuthorizeSecret(secret)
+	if errors.Is(err, auth.ErrTooManyConnections) {
+		return nil, nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return nil, nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return withKey(ctx, key), release, nil
+}
+
+func (g *grpcServer) authorizeUnary(
+	ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, release, err := g.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+func (g *grpcServer) authorizeStream(
+	srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx, release, err := g.authorize(ss.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorizedStream carries the key of its call
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// allow a channel to the key of a call
+func allow(ctx context.Context, channel string) error {
+	if key := keyOf(ctx); key != nil && !key.Allows(channel) {
+		return status.Errorf(codes.PermissionDenied, "the %s channel is not allowed for this api key", channel)
+	}
+	return nil
+}
+
+// Subscribe streams broadcasts that pass the filter
+func (g *grpcServer) Subscribe(req *marketwatchpb.SubscribeRequest, stream marketwatchpb.MarketWatch_SubscribeServer) error {
+	sub := &grpcSubscriber{
+		filter:  req,
+		key:     keyOf(stream.Context()),
+		scope:   scopeOf(stream.Context()),
+		send:    make(chan *grpcBroadcast, 256),
+		dropped: make(chan struct{}),
+	}
+	g.mutex.Lock()
+	g.subscribers[sub] = true
+	g.mutex.Unlock()
+	defer func() {
+		g.mutex.Lock()
+		delete(g.subscribers, sub)
+		g.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.dropped:
+			return status.Error(codes.ResourceExhausted, "subscriber fell too far behind")
+		case b := <-sub.send:
+			event := sub.eventFor(b)
+			if event == nil {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// publish a broadcast to the subscribers. Runs on the hub goroutine, so it
+// only queues the message: the subscribers convert it.
+func (g *grpcServer) publish(channel string, m interface{}) {
+	dropped := g.send(channel, m)
+	if len(dropped) == 0 {
+		return
+	}
+
+	// Drop subscribers that can not keep up, unless they are gone already
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for _, sub := range dropped {
+		if g.subscribers[sub] {
+			delete(g.subscribers, sub)
+			close(sub.dropped)
+		}
+	}
+}
+
+// send a broadcast to the subscribers, returning those too far behind
+func (g *grpcServer) send(channel string, m interface{}) []*grpcSubscriber {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	if len(g.subscribers) == 0 {
+		return nil
+	}
+
+	msg, ok := plainMessage(m).(Message)
+	if !ok {
+		return nil
+	}
+	b := &grpcBroadcast{channel: channel, msg: msg}
+
+	var dropped []*grpcSubscriber
+	for sub := range g.subscribers {
+		if !sub.wants(channel, msg.Action) {
+			continue
+		}
+		select {
+		case sub.send <- b:
+		default:
+			dropped = append(dropped, sub)
+		}
+	}
+	return dropped
+}
+
+// wants a channel and action, as far as can be told before converting
+func (sub *grpcSubscriber) wants(channel, action string) bool {
+	if sub.key != nil && !sub.key.Allows(channel) {
+		return false
+	}
+	if len(sub.filter.Channels) > 0 && !containsString(sub.filter.Channels, channel) {
+		return false
+	}
+	return len(sub.filter.Actions) == 0 || containsString(sub.filter.Actions, action)
+}
+
+// GetOrders in the market stores
+func (g *grpcServer) GetOrders(ctx context.Context, req *marketwatchpb.GetOrdersRequest) (*marketwatchpb.Orders, error) {
+	if req.RegionId == 0 && req.TypeId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "region_id or type_id is required")
+	}
+	if err := allow(ctx, "market"); err != nil {
+		return nil, err
+	}
+	sc := scopeOf(ctx)
+
+	orders := &marketwatchpb.Orders{}
+	g.mw.mmutex.RLock()
+	defer g.mw.mmutex.RUnlock()
+	for regionID, r := range g.mw.market {
+		if (req.RegionId != 0 && regionID != req.RegionId) || !sc.region(regionID) {
+			continue
+		}
+		r.Range(
+			func(k, v interface{}) bool {
+				o := v.(Order).Order
+				if (req.TypeId == 0 || o.TypeId == req.TypeId) &&
+					(req.LocationId == 0 || o.LocationId == req.LocationId) &&
+					sc.location(o.LocationId) {
+					orders.Orders = append(orders.Orders, toPbOrder(o))
+				}
+				return true
+			},
+		)
+	}
+	return orders, nil
+}
+
+// GetContracts in the contract stores
+func (g *grpcServer) GetContracts(ctx context.Context, req *marketwatchpb.GetContractsRequest) (*marketwatchpb.Contracts, error) {
+	if err := allow(ctx, "contract"); err != nil {
+		return nil, err
+	}
+	found := g.mw.findContracts(
+		contractQuery{
+			TypeID:       req.TypeId,
+			RegionID:     req.RegionId,
+			LocationID:   req.LocationId,
+			ContractType: req.Type,
+			Limit:        int(^uint(0) >> 1),
+			scope:        scopeOf(ctx),
+		},
+	)
+	return &marketwatchpb.Contracts{Contracts: toPbContracts(found)}, nil
+}
+
+// toPbEvent converts a broadcast, nil for payloads without a protobuf form
+func toPbEvent(channel string, m Message) *marketwatchpb.Event {
+	event := &marketwatchpb.Event{
+		Channel:  channel,
+		Action:   m.Action,
+		RegionId: m.RegionID,
+		Cycle:    m.Cycle,
+	}
+	switch p := m.Payload.(type) {
+	case []esi.GetMarketsRegionIdOrders200Ok:
+		orders := &marketwatchpb.Orders{}
+		for _, o := range p {
+			orders.Orders = append(orders.Orders, toPbOrder(o))
+		}
+		event.Payload = &marketwatchpb.Event_Orders{Orders: orders}
+	case []OrderChange:
+		changes := &marketwatchpb.OrderChanges{}
+		for _, c := range p {
+			changes.Changes = append(changes.Changes, toPbOrderChange(c))
+		}
+		event.Payload = &marketwatchpb.Event_OrderChanges{OrderChanges: changes}
+	case []FullContract:
+		event.Payload = &marketwatchpb.Event_Contracts{Contracts: &marketwatchpb.Contracts{Contracts: toPbContracts(p)}}
+	case []ContractChange:
+		changes := &marketwatchpb.ContractChanges{}
+		for _, c := range p {
+			changes.Changes = append(changes.Changes, toPbContractChange(c))
+		}
+		event.Payload = &marketwatchpb.Event_ContractChanges{ContractChanges: changes}
+	default:
+		return nil
+	}
+	return event
+}
+
+// filterPbEvent keeps the entries of an event that pass a filter, nil if none do
+func filterPbEvent(event *marketwatchpb.Event, f *marketwatchpb.SubscribeRequest) *marketwatchpb.Event {
+	if len(f.Channels) > 0 && !containsString(f.Channels, event.Channel) {
+		return nil
+	}
+	if len(f.Actions) > 0 && !containsString(f.Actions, event.Action) {
+		return nil
+	}
+	if len(f.TypeIds) == 0 && len(f.LocationIds) == 0 {
+		return event
+	}
+
+	match := func(typeIDs []int32, locationID int64) bool {
+		if len(f.LocationIds) > 0 && !containsInt64(f.LocationIds, locationID) {
+			return false
+		}
+		if len(f.TypeIds) == 0 {
+			return true
+		}
+		for _, t := range typeIDs {
+			if containsInt32(f.TypeIds, t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	filtered := &marketwatchpb.Event{
+		Channel:  event.Channel,
+		Action:   event.Action,
+		RegionId: event.RegionId,
+		Cycle:    event.Cycle,
+	}
+	switch p := event.Payload.(type) {
+	case *marketwatchpb.Event_Orders:
+		orders := &marketwatchpb.Orders{}
+		for _, o := range p.Orders.Orders {
+			if match([]int32{o.TypeId}, o.LocationId) {
+				orders.Orders = append(orders.Orders, o)
+			}
+		}
+		if len(orders.Orders) == 0 {
+			return nil
+		}
+		filtered.Payload = &marketwatchpb.Event_Orders{Orders: orders}
+	case *marketwatchpb.Event_OrderChanges:
+		changes := &marketwatchpb.OrderChanges{}
+		for _, c := range p.OrderChanges.Changes {
+			if match([]int32{c.TypeId}, c.LocationId) {
+				changes.Changes = append(changes.Changes, c)
+			}
+		}
+		if len(changes.Changes) == 0 {
+			return nil
+		}
+		filtered.Payload = &marketwatchpb.Event_OrderChanges{OrderChanges: changes}
+	case *marketwatchpb.Event_Contracts:
+		contracts := &marketwatchpb.Contracts{}
+		for _, c := range p.Contracts.Contracts {
+			if match(pbItemTypes(c.Items), c.Contract.GetStartLocationId()) {
+				contracts.Contracts = append(contracts.Contracts, c)
+			}
+		}
+		if len(contracts.Contracts) == 0 {
+			return nil
+		}
+		filtered.Payload = &marketwatchpb.Event_Contracts{Contracts: contracts}
+	case *marketwatchpb.Event_ContractChanges:
+		changes := &marketwatchpb.ContractChanges{}
+		for _, c := range p.ContractChanges.Changes {
+			if match(pbItemTypes(c.Items), c.LocationId) {
+				changes.Changes = append(changes.Changes, c)
+			}
+		}
+		if len(changes.Changes) == 0 {
+			return nil
+		}
+		filtered.Payload = &marketwatchpb.Event_ContractChanges{ContractChanges: changes}
+	}
+	return filtered
+}
+
+func toPbOrder(o esi.GetMarketsRegionIdOrders200Ok) *marketwatchpb.Order {
+	return &marketwatchpb.Order{
+		OrderId:      o.OrderId,
+		Duration:     o.Duration,
+		IsBuyOrder:   o.IsBuyOrder,
+		Issued:       toPbTime(o.Issued),
+		LocationId:   o.LocationId,
+		MinVolume:    o.MinVolume,
+		Price:        o.Price,
+		Range:        o.Range_,
+		SystemId:     o.SystemId,
+		TypeId:       o.TypeId,
+		VolumeRemain: o.VolumeRemain,
+		VolumeTotal:  o.VolumeTotal,
+	}
+}
+
+func toPbOrderChange(c OrderChange) *marketwatchpb.OrderChange {
+	return &marketwatchpb.OrderChange{
+		OrderId:      c.OrderID,
+		LocationId:   c.LocationId,
+		TypeId:       c.TypeID,
+		VolumeChange: c.VolumeChange,
+		VolumeRemain: c.VolumeRemain,
+		Price:        c.Price,
+		Duration:     c.Duration,
+		IsBuyOrder:   c.IsBuyOrder,
+		Issued:       toPbTime(c.Issued),
+		TimeChanged:  toPbTime(c.TimeChanged),
+	}
+}
+
+func toPbContracts(contracts []FullContract) []*marketwatchpb.FullContract {
+	converted := make([]*marketwatchpb.FullContract, 0, len(contracts))
+	for _, c := range contracts {
+		converted = append(converted, toPbContract(c))
+	}
+	return converted
+}
+
+func toPbContract(c FullContract) *marketwatchpb.FullContract {
+	contract := c.Contract
+	full := &marketwatchpb.FullContract{
+		Contract: &marketwatchpb.Contract{
+			Buyout:              contract.Buyout,
+			Collateral:          contract.Collateral,
+			ContractId:          contract.ContractId,
+			DateExpired:         toPbTime(contract.DateExpired),
+			DateIssued:          toPbTime(contract.DateIssued),
+			DaysToComplete:      contract.DaysToComplete,
+			EndLocationId:       contract.EndLocationId,
+			ForCorporation:      contract.ForCorporation,
+			IssuerCorporationId: contract.IssuerCorporationId,
+			IssuerId:            contract.IssuerId,
+			Price:               contract.Price,
+			Reward:              contract.Reward,
+			StartLocationId:     contract.StartLocationId,
+			Title:               contract.Title,
+			Type:                contract.Type_,
+			Volume:              contract.Volume,
+		},
+		Items:          toPbItems(c.Items),
+		Bids:           toPbBids(c.Bids),
+		EstimatedValue: c.EstimatedValue,
+		PriceRatio:     c.PriceRatio,
+	}
+	if c.Courier != nil {
+		full.Courier = &marketwatchpb.CourierInfo{
+			IskPerJump:      c.Courier.IskPerJump,
+			IskPerM3:        c.Courier.IskPerM3,
+			CollateralRatio: c.Courier.CollateralRatio,
+		}
+		if r := c.Courier.Route; r != nil {
+			full.Courier.Route = &marketwatchpb.CourierRoute{
+				StartSystemId: r.StartSystemID,
+				EndSystemId:   r.EndSystemID,
+				Jumps:         int32(r.Jumps),
+				HighSec:       int32(r.HighSec),
+				LowSec:        int32(r.LowSec),
+				NullSec:       int32(r.NullSec),
+			}
+		}
+	}
+	return full
+}
+
+func toPbContractChange(c ContractChange) *marketwatchpb.ContractChange {
+	return &marketwatchpb.ContractChange{
+		ContractId:  c.ContractId,
+		LocationId:  c.LocationId,
+		Expired:     c.Expired,
+		Reason:      c.Reason,
+		DateExpired: toPbTime(c.DateExpired),
+		Bids:        toPbBids(c.Bids),
+		Items:       toPbItems(c.Items),
+		Price:       c.Price,
+		Type:        c.Type_,
+		TimeChanged: toPbTime(c.TimeChanged),
+	}
+}
+
+func toPbItems(items []esi.GetContractsPublicItemsContractId200Ok) []*marketwatchpb.ContractItem {
+	converted := make([]*marketwatchpb.ContractItem, 0, len(items))
+	for _, i := range items {
+		converted = append(
+			converted, &marketwatchpb.ContractItem{
+				IsBlueprintCopy:    i.IsBlueprintCopy,
+				IsIncluded:         i.IsIncluded,
+				ItemId:             i.ItemId,
+				MaterialEfficiency: i.MaterialEfficiency,
+				Quantity:           i.Quantity,
+				RecordId:           i.RecordId,
+				Runs:               i.Runs,
+				TimeEfficiency:     i.TimeEfficiency,
+				TypeId:             i.TypeId,
+			},
+		)
+	}
+	return converted
+}
+
+func toPbBids(bids []esi.GetContractsPublicBidsContractId200Ok) []*marketwatchpb.ContractBid {
+	converted := make([]*marketwatchpb.ContractBid, 0, len(bids))
+	for _, b := range bids {
+		converted = append(
+			converted, &marketwatchpb.ContractBid{
+				Amount:  b.Amount,
+				BidId:   b.BidId,
+				DateBid: toPbTime(b.DateBid),
+			},
+		)
+	}
+	return converted
+}
+
+// toPbTime leaves zero times unset
+func toPbTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func pbItemTypes(items []*marketwatchpb.ContractItem) []int32 {
+	types := make([]int32, 0, len(items))
+	for _, i := range items {
+		if i.IsIncluded {
+			types = append(types, i.TypeId)
+		}
+	}
+	return types
+}
+
+func containsString(list []string, v string) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt32(list []int32, v int32) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(list []int64, v int64) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package marketwatch
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/contorno/eve-marketwatch/marketwatchpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishDropsSlowSubscribers(t *testing.T) {
+	g := newGRPCServer(&MarketWatch{})
+	slow := &grpcSubscriber{
+		filter:  &marketwatchpb.SubscribeRequest{},
+		send:    make(chan *grpcBroadcast, 1),
+		dropped: make(chan struct{}),
+	}
+	fast := &grpcSubscriber{
+		filter:  &marketwatchpb.SubscribeRequest{},
+		send:    make(chan *grpcBroadcast, 16),
+		dropped: make(chan struct{}),
+	}
+	g.subscribers[slow] = true
+	g.subscribers[fast] = true
+
+	// Subscribers come and go while broadcasts are published
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other := &grpcSubscriber{
+				filter:  &marketwatchpb.SubscribeRequest{Channels: []string{"contract"}},
+				send:    make(chan *grpcBroadcast),
+				dropped: make(chan struct{}),
+			}
+			g.mutex.Lock()
+			g.subscribers[other] = true
+			g.mutex.Unlock()
+			g.mutex.Lock()
+			delete(g.subscribers, other)
+			g.mutex.Unlock()
+		}()
+	}
+	message := Message{Action: "addition", RegionID: 1, Payload: orders(1)}
+	for i := 0; i < 3; i++ {
+		g.publish("market", message)
+	}
+	wg.Wait()
+
+	assert.Len(t, fast.send, 3)
+	_, open := <-slow.dropped
+	assert.False(t, open)
+	g.mutex.RLock()
+	assert.Equal(t, map[*grpcSubscriber]bool{fast: true}, g.subscribers)
+	g.mutex.RUnlock()
+}
+
+func TestPublishConvertsOnSubscribers(t *testing.T) {
+	g := newGRPCServer(&MarketWatch{})
+	subscriber := func(filter *marketwatchpb.SubscribeRequest, sc *scope) *grpcSubscriber {
+		sub := &grpcSubscriber{
+			filter:  filter,
+			scope:   sc,
+			send:    make(chan *grpcBroadcast, 1),
+			dropped: make(chan struct{}),
+		}
+		g.subscribers[sub] = true
+		return sub
+	}
+	first := subscriber(&marketwatchpb.SubscribeRequest{}, nil)
+	second := subscriber(&marketwatchpb.SubscribeRequest{}, nil)
+	scoped := subscriber(&marketwatchpb.SubscribeRequest{}, &scope{regions: map[int64]bool{2: true}})
+	contracts := subscriber(&marketwatchpb.SubscribeRequest{Channels: []string{"contract"}}, nil)
+	additions := subscriber(&marketwatchpb.SubscribeRequest{Actions: []string{"addition"}}, nil)
+
+	g.publish("market", Message{Action: "change", RegionID: 1, Payload: orders(1, 2)})
+
+	// Queued as is, and only for subscribers that may want it
+	b := <-first.send
+	assert.Nil(t, b.event)
+	assert.Len(t, contracts.send, 0)
+	assert.Len(t, additions.send, 0)
+
+	// Unscoped subscribers share one conversion
+	event := first.eventFor(b)
+	assert.Len(t, event.GetOrders().Orders, 2)
+	assert.Same(t, event, second.eventFor(<-second.send))
+
+	// Scoped ones convert their part
+	assert.Nil(t, scoped.eventFor(<-scoped.send))
+}
@@ -0,0 +1,196 @@
+package marketwatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+)
+
+const (
+	// Regions are stale after this many cache windows without a pull
+	defaultStaleFactor = 3.0
+
+	// Cache window assumed until a region's first pull
+	defaultCacheWindow = 5 * time.Minute
+)
+
+// Freshness of a region's market or contracts
+type Freshness struct {
+	RegionID    int64      `json:"region_id"`
+	Channel     string     `json:"channel"`
+	Ready       bool       `json:"ready"`
+	Stale       bool       `json:"stale"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastPull    *time.Time `json:"last_pull,omitempty"`
+	Age         float64    `json:"age_seconds"`
+	Window      float64    `json:"window_seconds"`
+}
+
+// HealthReport is the body of /healthz and /readyz
+type HealthReport struct {
+	Status  string      `json:"status"`
+	Regions []Freshness `json:"regions"`
+}
+
+type freshnessKey struct {
+	regionID int64
+	channel  string
+}
+
+type freshness struct {
+	lastSuccess time.Time
+	lastPull    time.Time
+	window      time.Duration
+}
+
+// healthTracker follows the last successful pull and cycle of every worker.
+// Readiness takes a whole cycle, staleness only a pull.
+type healthTracker struct {
+	mutex   sync.RWMutex
+	started time.Time
+	factor  float64
+	now     func() time.Time
+
+	// workers started, nil until the region list is known
+	regions map[freshnessKey]*freshness
+}
+
+func newHealthTracker(factor float64) *healthTracker {
+	return &healthTracker{
+		started: time.Now(),
+		factor:  factor,
+		now:     time.Now,
+	}
+}
+
+// expect a worker for a region
+func (h *healthTracker) expect(regionID int64, channel string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.regions == nil {
+		h.regions = make(map[freshnessKey]*freshness)
+	}
+	h.regions[freshnessKey{regionID, channel}] = &freshness{window: defaultCacheWindow}
+}
+
+// pulled records a successful pull from ESI: the first page of a cycle, or
+// the items of a contract along the way, as a contract cycle can take many
+// cache windows
+func (h *healthTracker) pulled(status CycleStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	f, ok := h.regions[freshnessKey{status.RegionID, status.Channel}]
+	if !ok {
+		return
+	}
+	f.lastPull = h.now()
+	f.setWindow(status)
+}
+
+// complete records a successful cycle, which counts as a pull too
+func (h *healthTracker) complete(status CycleStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	f, ok := h.regions[freshnessKey{status.RegionID, status.Channel}]
+	if !ok {
+		return
+	}
+	f.lastSuccess = h.now()
+	f.lastPull = f.lastSuccess
+	f.setWindow(status)
+}
+
+// setWindow to the cache window of a cycle, when known
+func (f *freshness) setWindow(status CycleStatus) {
+	if window := status.Expires.Sub(status.Started); window > 0 {
+		f.window = window
+	}
+}
+
+// report the freshness of every region
+func (h *healthTracker) report() (ready, healthy bool, regions []Freshness) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	now := h.now()
+	ready = h.regions != nil
+	healthy = true
+	for k, f := range h.regions {
+		since := f.lastPull
+		if since.IsZero() {
+			since = h.started
+		}
+		age := now.Sub(since)
+		r := Freshness{
+			RegionID: k.regionID,
+			Channel:  k.channel,
+			Ready:    !f.lastSuccess.IsZero(),
+			Stale:    float64(age) > h.factor*float64(f.window),
+			Age:      age.Seconds(),
+			Window:   f.window.Seconds(),
+		}
+		if r.Ready {
+			lastSuccess := f.lastSuccess
+			r.LastSuccess = &lastSuccess
+		}
+		if !f.lastPull.IsZero() {
+			lastPull := f.lastPull
+			r.LastPull = &lastPull
+		}
+		ready = ready && r.Ready
+		healthy = healthy && !r.Stale
+		regions = append(regions, r)
+	}
+	sort.Slice(
+		regions, func(i, j int) bool {
+			if regions[i].RegionID != regions[j].RegionID {
+				return regions[i].RegionID < regions[j].RegionID
+			}
+			return regions[i].Channel > regions[j].Channel
+		},
+	)
+	return ready, healthy, regions
+}
+
+// serveHealth fails when a region has not been pulled for too long
+func (s *MarketWatch) serveHealth(w http.ResponseWriter, r *http.Request) {
+	_, healthy, regions := s.health.report()
+	if healthy {
+		s.writeHealth(w, http.StatusOK, HealthReport{Status: "ok", Regions: regions})
+		return
+	}
+	s.writeHealth(w, http.StatusServiceUnavailable, HealthReport{Status: "stale", Regions: regions})
+}
+
+// serveReady succeeds once every region has completed a cycle
+func (s *MarketWatch) serveReady(w http.ResponseWriter, r *http.Request) {
+	ready, _, regions := s.health.report()
+	if ready {
+		s.writeHealth(w, http.StatusOK, HealthReport{Status: "ready", Regions: regions})
+		return
+	}
+	s.writeHealth(w, http.StatusServiceUnavailable, HealthReport{Status: "starting", Regions: regions})
+}
+
+func (s *MarketWatch) writeHealth(w http.ResponseWriter, code int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		s.log.Error("writing the health report", logging.Error, err)
+	}
+}
+
+func staleFactorFromEnv() (float64, error) {
+	raw := os.Getenv("HEALTH_STALE_FACTOR")
+	if raw == "" {
+		return defaultStaleFactor, nil
+	}
+	return strconv.ParseFloat(raw, 64)
+}
@@ -0,0 +1,116 @@
+package marketwatch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthTracker(t *testing.T) {
+	now := time.Now()
+	h := newHealthTracker(3)
+	h.started = now
+	h.now = func() time.Time { return now }
+
+	// Not ready until the regions are known
+	ready, healthy, regions := h.report()
+	assert.False(t, ready)
+	assert.True(t, healthy)
+	assert.Empty(t, regions)
+
+	h.expect(1, "market")
+	h.expect(1, "contract")
+	ready, healthy, regions = h.report()
+	assert.False(t, ready)
+	assert.True(t, healthy)
+	assert.Len(t, regions, 2)
+	assert.Nil(t, regions[0].LastSuccess)
+
+	// Ready once every worker completed a cycle, taking its cache window
+	now = now.Add(time.Minute)
+	h.complete(CycleStatus{RegionID: 1, Channel: "market", Started: now, Expires: now.Add(10 * time.Minute)})
+	ready, _, _ = h.report()
+	assert.False(t, ready)
+	h.complete(CycleStatus{RegionID: 1, Channel: "contract", Started: now})
+	ready, healthy, regions = h.report()
+	assert.True(t, ready)
+	assert.True(t, healthy)
+	assert.Equal(t, "market", regions[0].Channel)
+	assert.Equal(t, (10 * time.Minute).Seconds(), regions[0].Window)
+	assert.Equal(t, defaultCacheWindow.Seconds(), regions[1].Window)
+	assert.Equal(t, now, *regions[0].LastSuccess)
+
+	// Stale after the factor of its window without a pull
+	now = now.Add(3*defaultCacheWindow + time.Second)
+	ready, healthy, regions = h.report()
+	assert.True(t, ready)
+	assert.False(t, healthy)
+	assert.False(t, regions[0].Stale)
+	assert.True(t, regions[1].Stale)
+
+	// and fresh again with the next
+	h.complete(CycleStatus{RegionID: 1, Channel: "contract", Started: now})
+	_, healthy, _ = h.report()
+	assert.True(t, healthy)
+
+	// Unknown workers are ignored
+	h.complete(CycleStatus{RegionID: 2, Channel: "market"})
+	_, _, regions = h.report()
+	assert.Len(t, regions, 2)
+}
+
+func TestHealthTrackerStaleBeforeFirstPull(t *testing.T) {
+	now := time.Now()
+	h := newHealthTracker(3)
+	h.started = now
+	h.now = func() time.Time { return now }
+	h.expect(1, "market")
+
+	now = now.Add(3*defaultCacheWindow + time.Second)
+	ready, healthy, regions := h.report()
+	assert.False(t, ready)
+	assert.False(t, healthy)
+
+	// Without a pull there is no last success
+	body, err := json.Marshal(regions[0])
+	assert.Nil(t, err)
+	assert.NotContains(t, string(body), "last_success")
+}
+
+func TestHealthTrackerLongContractCycle(t *testing.T) {
+	now := time.Now()
+	h := newHealthTracker(3)
+	h.started = now
+	h.now = func() time.Time { return now }
+	h.expect(1, "contract")
+
+	// The first page starts a cycle in a 30 minute cache window
+	window := 30 * time.Minute
+	status := CycleStatus{RegionID: 1, Channel: "contract", Started: now, Expires: now.Add(window)}
+	h.pulled(status)
+
+	// Fetching contract items for hours keeps it fresh, though not ready
+	for i := 0; i < 4*60; i++ {
+		now = now.Add(time.Minute)
+		h.pulled(status)
+		ready, healthy, regions := h.report()
+		assert.False(t, ready)
+		assert.True(t, healthy)
+		assert.Nil(t, regions[0].LastSuccess)
+		assert.Equal(t, now, *regions[0].LastPull)
+		assert.Equal(t, window.Seconds(), regions[0].Window)
+	}
+
+	h.complete(status)
+	ready, healthy, _ := h.report()
+	assert.True(t, ready)
+	assert.True(t, healthy)
+
+	// A worker that stops pulling goes stale
+	now = now.Add(3*window + time.Second)
+	_, healthy, regions := h.report()
+	assert.False(t, healthy)
+	assert.True(t, regions[0].Stale)
+}
@@ -3,13 +3,13 @@ package marketwatch
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
-	"log"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/goesi"
 	"github.com/contorno/goesi/esi"
 	"github.com/contorno/optional"
 	"github.com/getsentry/sentry-go"
@@ -26,10 +26,20 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 	// For totalization
 	wg := sync.WaitGroup{}
 
+	// Completed cycles, the version of the region's data
+	var cycle uint64
+
+	// Cycles failed in a row
+	var failures int
+
+	logger := logging.WithHub(s.log, localHub).With(logging.Channel, "market", logging.RegionID, regionID)
+
 	// Loop forever
 	for {
 		start := time.Now()
 		numOrders := 0
+		status := newCycleStatus(int64(regionID), "market", start)
+		cycleLog := logger.With(logging.CycleID, cycle+1)
 
 		// Return Channels
 		rchan := make(chan []esi.GetMarketsRegionIdOrders200Ok, 100000)
@@ -39,8 +49,9 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 			context.Background(), "all", regionID, nil,
 		)
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			cycleLog.Error("fetching the first page", logging.Error, err)
+			failures++
+			time.Sleep(s.cycleFailed(status, failures, err))
 			continue
 		}
 		rchan <- orders
@@ -48,16 +59,20 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 		// Figure out if there are more pages
 		pages, err := getPages(res)
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			cycleLog.Error("reading the page count", logging.Error, err)
+			failures++
+			time.Sleep(s.cycleFailed(status, failures, err))
 			continue
 		}
+		status.Pages = pages
+		status.Expires = goesi.CacheExpires(res)
 		duration := timeUntilCacheExpires(res)
 		if duration.Minutes() < 3 {
-			fmt.Printf("%d market too close to window: waiting %s\n", regionID, duration.String())
+			s.cycleDelayed(status, duration)
 			time.Sleep(duration)
 			continue
 		}
+		s.health.pulled(status)
 
 		// Get the other pages concurrently
 		for pages > 1 {
@@ -69,6 +84,9 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 					},
 				)
 
+				defer wg.Done() // release when done
+				pageLog := logging.WithHub(cycleLog, localHub).With(logging.Page, page)
+
 				// Throttle down request rate to avoid error limit.
 				sleepRandom(5, 0.5)
 
@@ -79,28 +97,28 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 					&esi.GetMarketsRegionIdOrdersOpts{Page: optional.NewInt32(page)},
 				)
 
+				if err != nil {
+					pageLog.Error("fetching a page", logging.Error, err)
+					echan <- err
+					return
+				}
+
 				defer func(Body io.ReadCloser) {
 					thisErr := Body.Close()
 					if thisErr != nil {
-						sentry.CaptureException(thisErr)
-						log.Println(thisErr)
+						pageLog.Error("closing the response", logging.Error, thisErr)
 					}
 				}(r.Body)
 
-				if err != nil {
-					echan <- err
-					return
-				}
-
 				// Are we too close to the end of the window?
 				duration = timeUntilCacheExpires(r)
 				if duration.Seconds() < 20 {
-					echan <- errors.New("market too close to end of window")
+					err := errors.New("market too close to end of window")
+					pageLog.Error("fetching a page", logging.Error, err)
+					echan <- err
 					return
 				}
 
-				defer wg.Done() // release when done
-
 				// Add the orders to the channel
 				rchan <- orders
 			}(pages, sentry.CurrentHub().Clone())
@@ -113,19 +131,31 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 		close(rchan)
 		close(echan)
 
+		var pageErrors []error
 		for err := range echan {
-			sentry.CaptureException(err)
-			log.Println(err)
-			// Start over if any requests failed
+			pageErrors = append(pageErrors, err)
+		}
+		// Start over if any requests failed
+		if len(pageErrors) > 0 {
+			failures++
+			time.Sleep(s.cycleFailed(status, failures, pageErrors...))
 			continue
 		}
 
+		// Orders of the first cycle were there before us, when is unknown
+		firstSeen := start
+		if cycle == 0 {
+			firstSeen = time.Time{}
+		}
+
 		var changes []OrderChange
 		var newOrders []esi.GetMarketsRegionIdOrders200Ok
 		// Add all the orders together
 		for o := range rchan {
 			for i := range o {
-				change, isNew := s.storeData(int64(regionID), Order{Touched: start, Order: o[i]})
+				change, isNew := s.storeData(
+					int64(regionID), Order{Touched: start, Order: o[i], FirstSeen: firstSeen},
+				)
 				numOrders++
 				if change.Changed && !isNew {
 					changes = append(changes, change)
@@ -136,41 +166,67 @@ func (s *MarketWatch) marketWorker(regionID int32, localHub *sentry.Hub) {
 			}
 		}
 		deletions := s.expireOrders(int64(regionID), start)
+		s.prices.update(int64(regionID), s.getMarketStore(int64(regionID)))
+		cycle++
+		failures = 0
+		held := s.snapshotOrders(int64(regionID), cycle)
 
 		// Log metrics
+		region := strconv.FormatInt(int64(regionID), 10)
 		metricMarketTimePull.With(
 			prometheus.Labels{
-				"locationID": strconv.FormatInt(int64(regionID), 10),
+				"locationID": region,
 			},
 		).Observe(float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond))
+		metricMarketOrders.With(prometheus.Labels{"locationID": region}).Set(float64(held))
+		metricMarketChanges.With(prometheus.Labels{"locationID": region, "kind": "addition"}).Add(float64(len(newOrders)))
+		metricMarketChanges.With(prometheus.Labels{"locationID": region, "kind": "change"}).Add(float64(len(changes)))
+		metricMarketChanges.With(prometheus.Labels{"locationID": region, "kind": "deletion"}).Add(float64(len(deletions)))
+
+		s.checkOrders(int64(regionID), "addition", newOrders)
+		s.checkOrderChanges(int64(regionID), "change", changes)
+		s.checkOrderChanges(int64(regionID), "deletion", deletions)
 
 		if len(newOrders) > 0 {
 			s.broadcast.Broadcast(
-				"market", Message{
-					Action:  "addition",
-					Payload: newOrders,
-				},
+				"market", enrichedMessage(
+					Message{Action: "addition", Payload: newOrders, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichOrders(newOrders)
+					},
+				),
 			)
 		}
 
 		if len(changes) > 0 {
 			s.broadcast.Broadcast(
-				"market", Message{
-					Action:  "change",
-					Payload: changes,
-				},
+				"market", enrichedMessage(
+					Message{Action: "change", Payload: changes, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichOrderChanges(changes)
+					},
+				),
 			)
 		}
 
 		if len(deletions) > 0 {
 			s.broadcast.Broadcast(
-				"market", Message{
-					Action:  "deletion",
-					Payload: deletions,
-				},
+				"market", enrichedMessage(
+					Message{Action: "deletion", Payload: deletions, RegionID: int64(regionID), Cycle: cycle},
+					func() interface{} {
+						return s.enrichOrderChanges(deletions)
+					},
+				),
 			)
 		}
 
+		status.Cycle = cycle
+		status.Additions = len(newOrders)
+		status.Changes = len(changes)
+		status.Deletions = len(deletions)
+		status.NextRun = time.Now().Add(duration)
+		s.cycleComplete(status)
+
 		// Sleep until the cache timer expires, plus a little.
 		time.Sleep(duration)
 	}
@@ -187,10 +243,49 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(10, 1.6, 20),
 		}, []string{"locationID"},
 	)
+
+	metricMarketOrders = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "market",
+			Name:      "orders",
+			Help:      "Orders held per region after its last cycle.",
+		}, []string{"locationID"},
+	)
+
+	metricMarketChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "market",
+			Name:      "changes",
+			Help:      "Orders added, changed and deleted per region.",
+		}, []string{"locationID", "kind"},
+	)
+
+	metricOrderLifetime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "market",
+			Name:      "order_lifetime",
+			Help:      "Seconds from an order showing up to it leaving the market.",
+			Buckets:   prometheus.ExponentialBuckets(60, 2, 18),
+		}, []string{"side"},
+	)
 )
 
+// orderSide labels buy and sell orders
+func orderSide(isBuyOrder bool) string {
+	if isBuyOrder {
+		return "buy"
+	}
+	return "sell"
+}
+
 func init() {
 	prometheus.MustRegister(
 		metricMarketTimePull,
+		metricMarketOrders,
+		metricMarketChanges,
+		metricOrderLifetime,
 	)
 }
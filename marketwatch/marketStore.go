@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/contorno/goesi/esi"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Order wrapper to find last touch time.
@@ -12,21 +13,10 @@ import (
 type Order struct {
 	Touched time.Time
 	Order   esi.GetMarketsRegionIdOrders200Ok
-}
 
-// OrderChange Details of what changed on an order
-type OrderChange struct {
-	OrderID      int64     `json:"order_id"`
-	LocationId   int64     `json:"location_id"`
-	TypeID       int32     `json:"type_id"`
-	VolumeChange int32     `json:"volume_change,omitempty"`
-	VolumeRemain int32     `json:"volume_remain,omitempty"`
-	Price        float64   `json:"price"`
-	Duration     int32     `json:"duration,omitempty"`
-	IsBuyOrder   bool      `json:"is_buy_order,omitempty"`
-	Issued       time.Time `json:"issued,omitempty"`
-	Changed      bool      `json:"-"`
-	TimeChanged  time.Time `json:"time_changed"`
+	// When the order showed up, zero for orders already there when we
+	// started watching
+	FirstSeen time.Time
 }
 
 // storeData returns changes or true if the item is new
@@ -52,21 +42,29 @@ func (s *MarketWatch) storeData(locationID int64, order Order) (OrderChange, boo
 			change.Price = order.Order.Price
 			change.Duration = order.Order.Duration
 		}
+		order.FirstSeen = cOrder.FirstSeen
 		sMap.Store(order.Order.OrderId, order)
 		return change, false
 	}
 	return change, true
 }
 
+// expireOrders not seen since t, recording the lifetime of those we saw
+// show up
 func (s *MarketWatch) expireOrders(locationID int64, t time.Time) []OrderChange {
 	sMap := s.getMarketStore(locationID)
 	var changes []OrderChange
+	now := time.Now()
 
 	// Find any expired orders
 	sMap.Range(
 		func(k, v interface{}) bool {
 			o := v.(Order)
 			if t.After(o.Touched) {
+				if !o.FirstSeen.IsZero() {
+					metricOrderLifetime.With(prometheus.Labels{"side": orderSide(o.Order.IsBuyOrder)}).
+						Observe(now.Sub(o.FirstSeen).Seconds())
+				}
 				changes = append(
 					changes, OrderChange{
 						OrderID:      o.Order.OrderId,
@@ -0,0 +1,32 @@
+package marketwatch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contorno/goesi/esi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreDataKeepsFirstSeen(t *testing.T) {
+	s := &MarketWatch{market: map[int64]*sync.Map{1: {}}}
+	seen := time.Now().Add(-time.Hour)
+
+	_, isNew := s.storeData(1, Order{Touched: seen, FirstSeen: seen, Order: esi.GetMarketsRegionIdOrders200Ok{OrderId: 1, Price: 10}})
+	assert.True(t, isNew)
+
+	// Updates, whether changed or not, keep when it showed up
+	now := time.Now()
+	change, isNew := s.storeData(1, Order{Touched: now, FirstSeen: now, Order: esi.GetMarketsRegionIdOrders200Ok{OrderId: 1, Price: 11}})
+	assert.False(t, isNew)
+	assert.True(t, change.Changed)
+	s.storeData(1, Order{Touched: now, FirstSeen: now, Order: esi.GetMarketsRegionIdOrders200Ok{OrderId: 1, Price: 11}})
+
+	v, _ := s.getMarketStore(1).Load(int64(1))
+	assert.Equal(t, seen, v.(Order).FirstSeen)
+	assert.Equal(t, now, v.(Order).Touched)
+
+	// and leaving, it is gone
+	assert.Len(t, s.expireOrders(1, now.Add(time.Second)), 1)
+}
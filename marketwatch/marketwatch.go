@@ -1,18 +1,29 @@
 package marketwatch
 
 import (
-	"log"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/auth"
+	"github.com/contorno/eve-marketwatch/journal"
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/rules"
+	"github.com/contorno/eve-marketwatch/sde"
+	"github.com/contorno/eve-marketwatch/webhook"
 	"github.com/contorno/eve-marketwatch/wsbroadcast"
 	"github.com/getsentry/sentry-go"
+	"golang.org/x/exp/slog"
 
 	"github.com/contorno/goesi"
 )
 
+// Channels clients can subscribe to
+var Channels = []string{"market", "contract", "deals", "alerts", "status"}
+
 // MarketWatch provides CCP Market Data
 type MarketWatch struct {
 	// goesi client
@@ -26,12 +37,82 @@ type MarketWatch struct {
 	contracts map[int64]*sync.Map
 	mmutex    sync.RWMutex // Market mutex for the main map
 	cmutex    sync.RWMutex // Contract mutex for the main map
+
+	// item type index of the contracts
+	contractIndex *contractIndex
+
+	// hub prices for contract appraisal
+	prices    *priceBook
+	dealRatio float64
+
+	// static data, nil without SDE_PATH
+	static *sde.Store
+
+	// stargate routes for courier contracts
+	routes *routeCache
+
+	// names for enriched payloads
+	universe *universeCache
+
+	// alert rules
+	rules *rules.Engine
+
+	// webhook deliveries, nil without WEBHOOKS_PATH
+	webhooks *webhook.Sink
+
+	// broadcast journal, nil without JOURNAL_PATH
+	journal *journal.Writer
+
+	// address of the public websocket and api
+	publicAddr string
+
+	// gRPC address, not served when empty
+	grpcAddr string
+
+	// marketwatch to relay instead of pulling ESI, and its API key
+	upstream    string
+	upstreamKey string
+
+	// freshness of the workers
+	health *healthTracker
+
+	// API keys, nil when access is open
+	keys *auth.Keyring
+
+	log *slog.Logger
+
+	// Regions as their last cycle left them, for dumps
+	orderSnapshots    sync.Map // regionID -> []esi.GetMarketsRegionIdOrders200Ok
+	contractSnapshots sync.Map // regionID -> []FullContract
 }
 
 // NewMarketWatch creates a new MarketWatch microservice
 func NewMarketWatch() (*MarketWatch, error) {
+	hubs, err := appraisalHubsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	dealRatio, err := dealRatioFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	staleFactor, err := staleFactorFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// Static data is optional, couriers are not routed without it
+	var static *sde.Store
+	if path := os.Getenv("SDE_PATH"); path != "" {
+		static, err = sde.NewStore(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	httpclient := &http.Client{
 		Transport: &APITransport{
+			log: logging.For("esi"),
 			next: &http.Transport{
 				MaxIdleConns: 200,
 				DialContext: (&net.Dialer{
@@ -47,44 +128,200 @@ func NewMarketWatch() (*MarketWatch, error) {
 		},
 	}
 
+	ruleEngine, err := rules.NewEngine(os.Getenv("RULES_PATH"), static)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks *webhook.Sink
+	if path := os.Getenv("WEBHOOKS_PATH"); path != "" {
+		outbox := os.Getenv("WEBHOOK_OUTBOX")
+		if outbox == "" {
+			outbox = "outbox"
+		}
+		webhooks, err = webhook.NewSink(path, outbox)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var journalWriter *journal.Writer
+	if path := os.Getenv("JOURNAL_PATH"); path != "" {
+		var retention time.Duration
+		if raw := os.Getenv("JOURNAL_RETENTION"); raw != "" {
+			retention, err = time.ParseDuration(raw)
+			if err != nil {
+				return nil, err
+			}
+		}
+		journalWriter, err = journal.NewWriter(path, retention)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	esiClient := goesi.NewAPIClient(
+		httpclient,
+		"admin@eve.watch",
+	)
+
+	broadcast := wsbroadcast.NewHub(Channels)
+	broadcast.AddOptions(enrichOption)
+	broadcast.KeepHistory(256)
+	broadcast.DescribeActions(messageAction)
+	broadcast.SetLogger(logging.For("wsbroadcast"))
+
+	// Stream access is open to all without keys
+	var keys *auth.Keyring
+	if path := os.Getenv("AUTH_KEYS_PATH"); path != "" {
+		keys, err = auth.NewKeyring(path)
+		if err != nil {
+			return nil, err
+		}
+		broadcast.Authorize(authorizer(keys))
+	}
+	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
+		broadcast.AllowOrigins(strings.Split(origins, ",")...)
+	}
+
 	return &MarketWatch{
 		// ESI Client
-		esi: goesi.NewAPIClient(
-			httpclient,
-			"admin@eve.watch",
-		),
+		esi: esiClient,
 
 		// Websocket Broadcaster
-		broadcast: wsbroadcast.NewHub([]string{"market", "contract"}),
+		broadcast: broadcast,
 
 		// Market Data Map
 		market:    make(map[int64]*sync.Map),
 		contracts: make(map[int64]*sync.Map),
+
+		// Contract item index
+		contractIndex: newContractIndex(),
+
+		// Contract appraisal
+		prices:    newPriceBook(hubs),
+		dealRatio: dealRatio,
+
+		// Static data
+		static:   static,
+		routes:   newRouteCache(static),
+		universe: newUniverseCache(static, esiClient),
+
+		// Alerts
+		rules: ruleEngine,
+
+		// Webhooks
+		webhooks: webhooks,
+
+		// Journal
+		journal: journalWriter,
+
+		// Listeners
+		publicAddr: envOr("PUBLIC_ADDR", ":3005"),
+		grpcAddr:   os.Getenv("GRPC_ADDR"),
+
+		// Relay
+		upstream:    os.Getenv("UPSTREAM_URL"),
+		upstreamKey: os.Getenv("UPSTREAM_KEY"),
+
+		// Health checks
+		health: newHealthTracker(staleFactor),
+
+		// Access
+		keys: keys,
+
+		log: logging.For("marketwatch"),
 	}, nil
 }
 
 func (s *MarketWatch) Run() error {
 	s.broadcast.OnRegister(s.dumpMarket)
 
+	// Reload static data when the files change
+	if s.static != nil {
+		go s.static.Watch(time.Minute, sentry.CurrentHub().Clone())
+	}
+
+	// Look up names the static data does not have
+	go s.universe.resolve(sentry.CurrentHub().Clone())
+
+	// Reload rules when the file changes
+	go s.rules.Watch(10*time.Second, sentry.CurrentHub().Clone())
+
+	// Post broadcasts to webhooks alongside the websocket clients
+	if s.webhooks != nil {
+		s.broadcast.OnBroadcast(s.publishWebhooks)
+		go s.webhooks.Run(sentry.CurrentHub().Clone())
+	}
+
+	// Record every broadcast
+	if s.journal != nil {
+		s.broadcast.OnBroadcast(s.recordJournal)
+		go s.journal.Run(sentry.CurrentHub().Clone())
+	}
+
+	// Stream broadcasts over gRPC
+	if s.grpcAddr != "" {
+		server := newGRPCServer(s)
+		s.broadcast.OnBroadcast(server.publish)
+		go server.serve(s.grpcAddr, sentry.CurrentHub().Clone())
+	}
+
 	// Start the websocket handler
 	go s.broadcast.Run(sentry.CurrentHub().Clone())
+	go s.heartbeat(sentry.CurrentHub().Clone())
 
-	err := s.startUpMarketWorkers()
-	if err != nil {
-		return err
+	// Relays get their data from another marketwatch
+	if s.upstream != "" {
+		go s.relay(s.upstream, s.upstreamKey, sentry.CurrentHub().Clone())
+	} else {
+		err := s.startUpMarketWorkers()
+		if err != nil {
+			return err
+		}
 	}
 
+	// The public api has its own mux, metrics and debugging are served apart
+	mux := http.NewServeMux()
+
+	// Health checks
+	mux.HandleFunc("/healthz", s.serveHealth)
+	mux.HandleFunc("/readyz", s.serveReady)
+
+	// Contract search
+	mux.HandleFunc("/contracts/search", s.guard("contract", s.searchContracts))
+	mux.HandleFunc("/contracts/courier", s.guard("contract", s.searchCouriers))
+
+	// Stored alerts, rules are managed on the admin listener
+	mux.HandleFunc("/alerts", s.guard("alerts", readOnly(s.serveAlerts)))
+
+	// Server-sent events for clients that can not use websockets
+	mux.HandleFunc(
+		"/events",
+		func(w http.ResponseWriter, r *http.Request) {
+			err := s.broadcast.ServeSSE(w, r)
+			if err != nil {
+				s.log.Error("serving server-sent events", logging.ClientAddr, r.RemoteAddr, logging.Error, err)
+			}
+		},
+	)
+
 	// Handler for the websocket
-	http.HandleFunc(
+	mux.HandleFunc(
 		"/",
 		func(w http.ResponseWriter, r *http.Request) {
 			err := s.broadcast.ServeWs(w, r)
 			if err != nil {
-				sentry.CaptureException(err)
-				log.Println(err)
+				s.log.Error("serving a websocket", logging.ClientAddr, r.RemoteAddr, logging.Error, err)
 			}
 		},
 	)
 
-	return http.ListenAndServe(":3005", nil) //nolint:gosec
+	s.log.Info("serving the market watch", "addr", s.publicAddr)
+	return http.ListenAndServe(s.publicAddr, mux) //nolint:gosec
+}
+
+// ServeRules manages the alert rules, for the admin listener only
+func (s *MarketWatch) ServeRules(w http.ResponseWriter, r *http.Request) {
+	s.rules.ServeRules(w, r)
 }
@@ -1,61 +1,53 @@
 package marketwatch
 
 import (
-	"github.com/contorno/goesi/esi"
+	"github.com/contorno/eve-marketwatch/stream"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
 )
 
-// Message wraps different payloads for the websocket interface
-type Message struct {
-	Action  string      `json:"action"`
-	Payload interface{} `json:"payload"`
-}
+// Messages and payloads of the stream, shared with the client
+type (
+	Message        = stream.Message
+	OrderChange    = stream.OrderChange
+	FullContract   = stream.FullContract
+	CourierInfo    = stream.CourierInfo
+	CourierRoute   = stream.CourierRoute
+	ContractChange = stream.ContractChange
+	SnapshotRegion = stream.SnapshotRegion
+	CycleStatus    = stream.CycleStatus
+	Heartbeat      = stream.Heartbeat
+)
 
-func (s *MarketWatch) dumpMarket(channels map[string]bool, send chan interface{}) {
-	// Prevent changes to the map while we loop
-	s.mmutex.RLock()
-	defer s.mmutex.RUnlock()
+// Reasons a contract left the public contract list
+const (
+	ContractAccepted   = stream.ContractAccepted
+	ContractInProgress = stream.ContractInProgress
+	ContractExpired    = stream.ContractExpired
+	ContractRemoved    = stream.ContractRemoved
+)
 
-	// loop all the locations
-	if channels["market"] {
-		for _, r := range s.market {
-			// Build a list
-			var m []esi.GetMarketsRegionIdOrders200Ok
-			r.Range(
-				func(k, v interface{}) bool {
-					o := v.(Order)
-					m = append(m, o.Order)
-					return true
-				},
-			)
-			// send the list out
-			if len(m) > 0 {
-				send <- Message{
-					Action:  "addition",
-					Payload: m,
-				}
-			}
-		}
+// plainMessage of a broadcast, without options applied
+func plainMessage(m interface{}) interface{} {
+	if o, ok := m.(*wsbroadcast.OptionalMessage); ok {
+		return o.For(nil)
 	}
+	return m
+}
 
-	// loop all the locations
-	if channels["contract"] {
-		for _, r := range s.contracts {
-			// Build a list
-			var m []FullContract
-			r.Range(
-				func(k, v interface{}) bool {
-					o := v.(Contract)
-					m = append(m, o.Contract)
-					return true
-				},
-			)
-			// send the list out
-			if len(m) > 0 {
-				send <- Message{
-					Action:  "contractAddition",
-					Payload: m,
-				}
-			}
-		}
+// messageAction of a broadcast message
+func messageAction(m interface{}) string {
+	if msg, ok := plainMessage(m).(Message); ok {
+		return msg.Action
 	}
+	return ""
+}
+
+// publishWebhooks hands broadcasts to the webhook sink
+func (s *MarketWatch) publishWebhooks(channel string, m interface{}) {
+	s.webhooks.Publish(channel, messageAction(m), m)
+}
+
+// recordJournal hands broadcasts to the journal
+func (s *MarketWatch) recordJournal(channel string, m interface{}) {
+	s.journal.Publish(channel, plainMessage(m))
 }
@@ -2,10 +2,10 @@ package marketwatch
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
 	"github.com/getsentry/sentry-go"
 )
 
@@ -28,11 +28,19 @@ func (s *MarketWatch) startUpMarketWorkers() error {
 		}
 	}
 
+	// Expect every worker before the first starts, so readiness waits for all
+	for _, region := range regions {
+		if hasMarket(region) {
+			s.health.expect(int64(region), "market")
+			s.health.expect(int64(region), "contract")
+		}
+	}
+
 	for _, region := range regions {
 		s.createMarketStore(int64(region))
 		s.createContractStore(int64(region))
 		// Ignore non-market regions
-		if region < 11000000 || region == 11000031 {
+		if hasMarket(region) {
 			time.Sleep(time.Second * 1)
 			go s.marketWorker(region, sentry.CurrentHub().Clone())
 			go s.contractWorker(region, sentry.CurrentHub().Clone())
@@ -42,10 +50,14 @@ func (s *MarketWatch) startUpMarketWorkers() error {
 	defer func() {
 		err := res.Body.Close()
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			s.log.Error("closing the response", logging.Error, err)
 		}
 	}()
 
 	return nil
 }
+
+// hasMarket is false for wormhole and other regions without a market
+func hasMarket(regionID int32) bool {
+	return regionID < 11000000 || regionID == 11000031
+}
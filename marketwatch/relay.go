@@ -0,0 +1,454 @@
+package marketwatch
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/client"
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/stream"
+	"github.com/contorno/goesi/esi"
+	"github.com/getsentry/sentry-go"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// Reconnection delays, doubling from the first to the last
+	relayMinBackoff = time.Second
+	relayMaxBackoff = time.Minute
+
+	// Upstreams are given up on after this long without a message. The
+	// status channel sends a heartbeat every heartbeatInterval.
+	relayReadTimeout = 3 * heartbeatInterval
+)
+
+// Channels a relay subscribes to. Alerts are raised by the relay's own rules.
+var relayChannels = []string{"market", "contract", "deals", "status"}
+
+// channelOf the actions a relay passes on
+var channelOf = map[string]string{
+	"addition":         "market",
+	"change":           "market",
+	"deletion":         "market",
+	"contractAddition": "contract",
+	"contractChange":   "contract",
+	"contractDeletion": "contract",
+	"deal":             "deals",
+	"cycleComplete":    "status",
+	"cycleFailed":      "status",
+	"cycleDelayed":     "status",
+}
+
+// cycleKey of the data of a region, deals belong to the contract cycle
+type cycleKey struct {
+	regionID int64
+	channel  string
+}
+
+// relay keeps the stores in step with an upstream marketwatch
+type relay struct {
+	mw  *MarketWatch
+	log *slog.Logger
+
+	// Last cycle applied to each store
+	applied map[cycleKey]uint64
+
+	// Messages of each cycle until its cycleComplete
+	pending map[cycleKey][]Message
+
+	// The dump being read, nil outside of it
+	dump *relayDump
+}
+
+// relayDump is the upstream dump being read after connecting
+type relayDump struct {
+	started time.Time
+	regions []SnapshotRegion
+
+	// What differs from the stores as they were before the dump
+	orders          map[int64][]esi.GetMarketsRegionIdOrders200Ok
+	orderChanges    map[int64][]OrderChange
+	contracts       map[int64][]FullContract
+	contractChanges map[int64][]ContractChange
+}
+
+// relay the upstream to our own clients, reconnecting when it goes away.
+// Every connection starts with the upstream dump, which brings the stores up
+// to date, and the changes it finds are broadcast like any other cycle.
+func (s *MarketWatch) relay(upstream string, key string, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#relay")
+		},
+	)
+
+	r := &relay{
+		mw:      s,
+		log:     logging.WithHub(logging.For("relay"), localHub),
+		applied: make(map[cycleKey]uint64),
+		pending: make(map[cycleKey][]Message),
+	}
+
+	backoff := relayMinBackoff
+	for {
+		synced, err := r.follow(upstream, key)
+		if synced {
+			backoff = relayMinBackoff
+		}
+		r.log.Warn("upstream lost, reconnecting", "retry_in", backoff.String(), logging.Error, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > relayMaxBackoff {
+			backoff = relayMaxBackoff
+		}
+	}
+}
+
+// follow the upstream until the connection fails, reporting if the dump was
+// read in full
+func (r *relay) follow(upstream string, key string) (bool, error) {
+	address, err := relayURL(upstream)
+	if err != nil {
+		return false, err
+	}
+	header := http.Header{}
+	if key != "" {
+		header.Set("Authorization", "Bearer "+key)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  30 * time.Second,
+		EnableCompression: true,
+	}
+	conn, res, err := dialer.Dial(address, header)
+	if err != nil {
+		if res != nil {
+			return false, fmt.Errorf("%w: %s", err, res.Status)
+		}
+		return false, err
+	}
+	defer conn.Close() //nolint:errcheck
+	r.log.Info("following upstream", "upstream", upstream)
+
+	// Partial cycles of the last connection are covered by the dump
+	r.pending = make(map[cycleKey][]Message)
+	r.dump = nil
+
+	synced := false
+	for {
+		err = conn.SetReadDeadline(time.Now().Add(relayReadTimeout))
+		if err != nil {
+			return synced, err
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return synced, err
+		}
+
+		m, err := client.Decode(data)
+		if err != nil {
+			return synced, err
+		}
+		if m.Action == "snapshotEnd" {
+			synced = true
+		}
+		if err = r.handle(m); err != nil {
+			return synced, err
+		}
+	}
+}
+
+// relayURL subscribes an upstream address to the relayed channels
+func relayURL(upstream string) (string, error) {
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, c := range relayChannels {
+		q.Set(c, "1")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// handle a message of the upstream. An error means the stores can no longer
+// follow it, and a new dump is needed.
+func (r *relay) handle(m Message) error {
+	switch m.Action {
+	case "snapshotBegin":
+		r.beginDump(m.Payload.([]SnapshotRegion))
+	case "snapshotEnd":
+		r.endDump()
+	case "cycleComplete":
+		return r.completeCycle(m.Payload.(CycleStatus))
+	case "cycleFailed", "cycleDelayed":
+		r.mw.broadcast.Broadcast("status", m)
+	case "heartbeat":
+		// We send our own
+	default:
+		channel, ok := channelOf[m.Action]
+		if !ok {
+			r.log.Debug("ignoring upstream message", "action", m.Action)
+			return nil
+		}
+		if r.dump != nil {
+			r.dumpChunk(m)
+			return nil
+		}
+		key := cycleKey{m.RegionID, channel}
+		if channel == "deals" {
+			key.channel = "contract"
+		}
+		// The dump already holds this cycle
+		if m.Cycle <= r.applied[key] {
+			return nil
+		}
+		r.pending[key] = append(r.pending[key], m)
+	}
+	return nil
+}
+
+// beginDump starts reading the upstream dump
+func (r *relay) beginDump(regions []SnapshotRegion) {
+	r.dump = &relayDump{
+		started:         time.Now(),
+		regions:         regions,
+		orders:          make(map[int64][]esi.GetMarketsRegionIdOrders200Ok),
+		orderChanges:    make(map[int64][]OrderChange),
+		contracts:       make(map[int64][]FullContract),
+		contractChanges: make(map[int64][]ContractChange),
+	}
+
+	for _, region := range regions {
+		r.track(cycleKey{region.RegionID, region.Channel})
+	}
+}
+
+// track a store the first time the upstream mentions it
+func (r *relay) track(key cycleKey) {
+	if _, ok := r.applied[key]; ok {
+		return
+	}
+	r.applied[key] = 0
+	r.mw.relayStores(key.regionID)
+	r.mw.health.expect(key.regionID, key.channel)
+}
+
+// dumpChunk stores a message of the dump, noting how it differs from the
+// store
+func (r *relay) dumpChunk(m Message) {
+	dump := r.dump
+	switch p := m.Payload.(type) {
+	case []esi.GetMarketsRegionIdOrders200Ok:
+		for _, o := range p {
+			change, isNew := r.mw.storeData(m.RegionID, Order{Touched: dump.started, Order: o})
+			if isNew {
+				dump.orders[m.RegionID] = append(dump.orders[m.RegionID], o)
+			} else if change.Changed {
+				dump.orderChanges[m.RegionID] = append(dump.orderChanges[m.RegionID], change)
+			}
+		}
+	case []FullContract:
+		for _, c := range p {
+			change, isNew := r.mw.storeContract(m.RegionID, Contract{Touched: dump.started, Contract: c})
+			if isNew {
+				dump.contracts[m.RegionID] = append(dump.contracts[m.RegionID], c)
+			} else if change.Changed {
+				dump.contractChanges[m.RegionID] = append(dump.contractChanges[m.RegionID], change)
+			}
+		}
+	}
+}
+
+// endDump drops what the dump no longer holds and broadcasts the difference
+// as a cycle of each region
+func (r *relay) endDump() {
+	dump := r.dump
+	r.dump = nil
+	if dump == nil {
+		return
+	}
+
+	for _, region := range dump.regions {
+		regionID := region.RegionID
+		var messages []Message
+		switch region.Channel {
+		case "market":
+			deletions := r.mw.expireOrders(regionID, dump.started)
+			messages = appendCycle(messages, region, "addition", dump.orders[regionID])
+			messages = appendCycle(messages, region, "change", dump.orderChanges[regionID])
+			messages = appendCycle(messages, region, "deletion", deletions)
+		case "contract":
+			deletions := r.mw.expireContracts(regionID, dump.started, nil)
+			messages = appendCycle(messages, region, "contractAddition", dump.contracts[regionID])
+			messages = appendCycle(messages, region, "contractChange", dump.contractChanges[regionID])
+			messages = appendCycle(messages, region, "contractDeletion", deletions)
+		default:
+			continue
+		}
+
+		key := cycleKey{regionID, region.Channel}
+		r.applied[key] = region.Cycle
+		r.publish(key, region.Cycle, messages)
+		r.mw.health.complete(CycleStatus{RegionID: regionID, Channel: region.Channel})
+	}
+	r.log.Info("synced with upstream", "regions", len(dump.regions), "took", time.Since(dump.started).String())
+}
+
+// appendCycle appends a message of a region's cycle unless it is empty
+func appendCycle[T any](messages []Message, region SnapshotRegion, action string, payload []T) []Message {
+	if len(payload) == 0 {
+		return messages
+	}
+	return append(messages, Message{Action: action, Payload: payload, RegionID: region.RegionID, Cycle: region.Cycle})
+}
+
+// completeCycle applies the messages of a finished upstream cycle and
+// passes them on. Upstream cycles count up by one, so a cycle past the next
+// one means some were missed.
+func (r *relay) completeCycle(status CycleStatus) error {
+	key := cycleKey{status.RegionID, status.Channel}
+	r.track(key)
+	messages := r.pending[key]
+	delete(r.pending, key)
+	if status.Cycle <= r.applied[key] {
+		return nil
+	}
+	if status.Cycle > r.applied[key]+1 {
+		return fmt.Errorf(
+			"missed cycles %d to %d of the %s of region %d",
+			r.applied[key]+1, status.Cycle-1, status.Channel, status.RegionID,
+		)
+	}
+
+	var current []Message
+	for _, m := range messages {
+		if m.Cycle == status.Cycle {
+			current = append(current, m)
+		}
+	}
+	for _, m := range current {
+		r.apply(m)
+	}
+
+	r.applied[key] = status.Cycle
+	r.publish(key, status.Cycle, current)
+	r.mw.health.complete(status)
+	r.mw.broadcast.Broadcast(
+		"status", Message{
+			Action:   "cycleComplete",
+			Payload:  status,
+			RegionID: status.RegionID,
+			Cycle:    status.Cycle,
+		},
+	)
+	return nil
+}
+
+// apply a message of a cycle to the stores and check it against the rules
+func (r *relay) apply(m Message) {
+	s := r.mw
+	stream.Apply(relayStore{s}, m)
+	switch p := m.Payload.(type) {
+	case []esi.GetMarketsRegionIdOrders200Ok:
+		s.checkOrders(m.RegionID, m.Action, p)
+	case []OrderChange:
+		s.checkOrderChanges(m.RegionID, m.Action, p)
+	case []FullContract:
+		if m.Action == "contractAddition" {
+			s.checkContracts(m.RegionID, m.Action, p)
+		}
+	case []ContractChange:
+		s.checkContractChanges(m.RegionID, m.Action, p)
+	}
+}
+
+// relayStore applies the upstream's cycles to the stores
+type relayStore struct {
+	mw *MarketWatch
+}
+
+func (rs relayStore) PutOrder(regionID int64, o esi.GetMarketsRegionIdOrders200Ok) {
+	rs.mw.storeData(regionID, Order{Touched: time.Now(), Order: o})
+}
+
+func (rs relayStore) GetOrder(regionID, orderID int64) (esi.GetMarketsRegionIdOrders200Ok, bool) {
+	v, ok := rs.mw.getMarketStore(regionID).Load(orderID)
+	if !ok {
+		return esi.GetMarketsRegionIdOrders200Ok{}, false
+	}
+	return v.(Order).Order, true
+}
+
+func (rs relayStore) DeleteOrder(regionID, orderID int64) {
+	rs.mw.getMarketStore(regionID).Delete(orderID)
+}
+
+func (rs relayStore) PutContract(regionID int64, c FullContract) {
+	rs.mw.storeContract(regionID, Contract{Touched: time.Now(), Contract: c})
+}
+
+func (rs relayStore) GetContract(regionID int64, contractID int32) (FullContract, bool) {
+	v, ok := rs.mw.getContractStore(regionID).Load(contractID)
+	if !ok {
+		return FullContract{}, false
+	}
+	return v.(Contract).Contract, true
+}
+
+func (rs relayStore) DeleteContract(regionID int64, contractID int32) {
+	sMap := rs.mw.getContractStore(regionID)
+	if _, ok := sMap.LoadAndDelete(contractID); ok {
+		rs.mw.contractIndex.remove(contractID)
+	}
+}
+
+// publish a cycle of a store: the snapshot is taken first, like the
+// workers do, then the messages are broadcast
+func (r *relay) publish(key cycleKey, cycle uint64, messages []Message) {
+	s := r.mw
+	region := strconv.FormatInt(key.regionID, 10)
+	switch key.channel {
+	case "market":
+		s.prices.update(key.regionID, s.getMarketStore(key.regionID))
+		held := s.snapshotOrders(key.regionID, cycle)
+		metricMarketOrders.With(prometheus.Labels{"locationID": region}).Set(float64(held))
+	case "contract":
+		held := s.snapshotContracts(key.regionID, cycle)
+		metricContracts.With(prometheus.Labels{"locationID": region}).Set(float64(held))
+	}
+
+	for _, m := range messages {
+		s.broadcast.Broadcast(channelOf[m.Action], s.relayed(m))
+	}
+}
+
+// relayed form of a message, enriched by us when clients ask
+func (s *MarketWatch) relayed(m Message) interface{} {
+	switch p := m.Payload.(type) {
+	case []esi.GetMarketsRegionIdOrders200Ok:
+		return enrichedMessage(m, func() interface{} { return s.enrichOrders(p) })
+	case []OrderChange:
+		return enrichedMessage(m, func() interface{} { return s.enrichOrderChanges(p) })
+	case []FullContract:
+		return enrichedMessage(m, func() interface{} { return s.enrichContracts(p) })
+	case []ContractChange:
+		return enrichedMessage(m, func() interface{} { return s.enrichContractChanges(p) })
+	}
+	return m
+}
+
+// relayStores creates the stores of a region the first time it is seen
+func (s *MarketWatch) relayStores(regionID int64) {
+	if s.getMarketStore(regionID) == nil {
+		s.createMarketStore(regionID)
+	}
+	if s.getContractStore(regionID) == nil {
+		s.createContractStore(regionID)
+	}
+}
@@ -0,0 +1,217 @@
+package marketwatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/rules"
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/contorno/goesi/esi"
+	"github.com/getsentry/sentry-go"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// upstream serves a script of messages to each connection in turn, then
+// closes it
+type upstream struct {
+	server  *httptest.Server
+	scripts chan []Message
+}
+
+func newUpstream(t *testing.T) *upstream {
+	u := &upstream{scripts: make(chan []Message, 4)}
+	u.server = httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close() //nolint:errcheck
+				for _, m := range <-u.scripts {
+					if conn.WriteJSON(m) != nil {
+						return
+					}
+				}
+			},
+		),
+	)
+	t.Cleanup(u.server.Close)
+	return u
+}
+
+func (u *upstream) url() string {
+	return "ws" + strings.TrimPrefix(u.server.URL, "http")
+}
+
+// recorder keeps the broadcasts of a hub
+type recorder struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+func (rec *recorder) record(_ string, m interface{}) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	if msg, ok := plainMessage(m).(Message); ok {
+		rec.messages = append(rec.messages, msg)
+	}
+}
+
+// actions broadcast, once count of them have been
+func (rec *recorder) actions(t *testing.T, count int) []string {
+	var actions []string
+	assert.Eventually(
+		t, func() bool {
+			rec.mutex.Lock()
+			defer rec.mutex.Unlock()
+			actions = nil
+			for _, m := range rec.messages {
+				actions = append(actions, m.Action)
+			}
+			return len(actions) >= count
+		}, time.Second, time.Millisecond,
+	)
+	return actions
+}
+
+func newTestRelay(t *testing.T) (*relay, *recorder) {
+	engine, err := rules.NewEngine("", nil)
+	assert.Nil(t, err)
+
+	rec := &recorder{}
+	broadcast := wsbroadcast.NewHub(Channels)
+	broadcast.OnBroadcast(rec.record)
+	go broadcast.Run(sentry.CurrentHub().Clone())
+
+	s := &MarketWatch{
+		broadcast:     broadcast,
+		market:        make(map[int64]*sync.Map),
+		contracts:     make(map[int64]*sync.Map),
+		contractIndex: newContractIndex(),
+		prices:        newPriceBook(nil),
+		rules:         engine,
+		health:        newHealthTracker(defaultStaleFactor),
+		log:           logging.For("marketwatch"),
+	}
+	return &relay{
+		mw:      s,
+		log:     logging.For("relay"),
+		applied: make(map[cycleKey]uint64),
+		pending: make(map[cycleKey][]Message),
+	}, rec
+}
+
+func orders(ids ...int64) []esi.GetMarketsRegionIdOrders200Ok {
+	var list []esi.GetMarketsRegionIdOrders200Ok
+	for _, id := range ids {
+		list = append(list, esi.GetMarketsRegionIdOrders200Ok{OrderId: id, LocationId: 100, Price: 10})
+	}
+	return list
+}
+
+func storedOrder(r *relay, orderID int64) (esi.GetMarketsRegionIdOrders200Ok, bool) {
+	return relayStore{r.mw}.GetOrder(1, orderID)
+}
+
+func TestRelay(t *testing.T) {
+	up := newUpstream(t)
+	r, rec := newTestRelay(t)
+
+	dump := []SnapshotRegion{{RegionID: 1, Channel: "market", Cycle: 3, Count: 2}}
+	up.scripts <- []Message{
+		{Action: "snapshotBegin", Payload: dump},
+		{Action: "addition", RegionID: 1, Cycle: 3, Payload: orders(1, 2)},
+		{Action: "snapshotEnd", Payload: dump},
+
+		// Already in the dump
+		{Action: "addition", RegionID: 1, Cycle: 3, Payload: orders(9)},
+
+		// A whole cycle
+		{Action: "addition", RegionID: 1, Cycle: 4, Payload: orders(3)},
+		{Action: "change", RegionID: 1, Cycle: 4, Payload: []OrderChange{{OrderID: 1, Price: 11, VolumeRemain: 5}}},
+		{Action: "cycleComplete", Payload: CycleStatus{RegionID: 1, Channel: "market", Cycle: 4}},
+
+		// A cycle cut short by the connection
+		{Action: "deletion", RegionID: 1, Cycle: 5, Payload: []OrderChange{{OrderID: 2}}},
+	}
+
+	synced, err := r.follow(up.url(), "")
+	assert.True(t, synced)
+	assert.NotNil(t, err)
+
+	// The dump and the complete cycle are applied and passed on
+	assert.Equal(t, []string{"addition", "addition", "change", "cycleComplete"}, rec.actions(t, 4))
+	assert.Equal(t, uint64(4), r.applied[cycleKey{1, "market"}])
+	o, ok := storedOrder(r, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 11.0, o.Price)
+	_, ok = storedOrder(r, 3)
+	assert.True(t, ok)
+	_, ok = storedOrder(r, 9)
+	assert.False(t, ok)
+
+	// The partial cycle is not
+	_, ok = storedOrder(r, 2)
+	assert.True(t, ok)
+	ready, _, _ := r.mw.health.report()
+	assert.True(t, ready)
+
+	// Reconnecting, the dump drops what went while we were away and passes
+	// the difference on
+	dump = []SnapshotRegion{{RegionID: 1, Channel: "market", Cycle: 6, Count: 2}}
+	up.scripts <- []Message{
+		{Action: "snapshotBegin", Payload: dump},
+		{Action: "addition", RegionID: 1, Cycle: 6, Payload: orders(1, 3)},
+		{Action: "snapshotEnd", Payload: dump},
+
+		// Cycle 7 follows on
+		{Action: "deletion", RegionID: 1, Cycle: 7, Payload: []OrderChange{{OrderID: 3}}},
+		{Action: "cycleComplete", Payload: CycleStatus{RegionID: 1, Channel: "market", Cycle: 7}},
+	}
+	synced, err = r.follow(up.url(), "")
+	assert.True(t, synced)
+	assert.NotNil(t, err)
+
+	actions := rec.actions(t, 8)
+	assert.Equal(t, []string{"change", "deletion", "deletion", "cycleComplete"}, actions[4:])
+	assert.Empty(t, r.pending)
+	assert.Equal(t, uint64(7), r.applied[cycleKey{1, "market"}])
+	_, ok = storedOrder(r, 2)
+	assert.False(t, ok)
+	_, ok = storedOrder(r, 3)
+	assert.False(t, ok)
+}
+
+func TestRelayCycleGap(t *testing.T) {
+	up := newUpstream(t)
+	r, _ := newTestRelay(t)
+
+	dump := []SnapshotRegion{{RegionID: 1, Channel: "market", Cycle: 3, Count: 1}}
+	up.scripts <- []Message{
+		{Action: "snapshotBegin", Payload: dump},
+		{Action: "addition", RegionID: 1, Cycle: 3, Payload: orders(1)},
+		{Action: "snapshotEnd", Payload: dump},
+
+		// Cycles 4 and 5 never arrived
+		{Action: "addition", RegionID: 1, Cycle: 6, Payload: orders(2)},
+		{Action: "cycleComplete", Payload: CycleStatus{RegionID: 1, Channel: "market", Cycle: 6}},
+
+		// Not read, the relay reconnects for a new dump instead
+		{Action: "addition", RegionID: 1, Cycle: 7, Payload: orders(3)},
+		{Action: "cycleComplete", Payload: CycleStatus{RegionID: 1, Channel: "market", Cycle: 7}},
+	}
+
+	synced, err := r.follow(up.url(), "")
+	assert.True(t, synced)
+	assert.ErrorContains(t, err, "missed cycles 4 to 5")
+	assert.Equal(t, uint64(3), r.applied[cycleKey{1, "market"}])
+	_, ok := storedOrder(r, 2)
+	assert.False(t, ok)
+}
@@ -0,0 +1,144 @@
+package marketwatch
+
+import (
+	"sort"
+
+	"github.com/contorno/goesi/esi"
+)
+
+// Orders or contracts per dump message
+const dumpChunkSize = 5000
+
+type orderSnapshot struct {
+	cycle  uint64
+	orders []esi.GetMarketsRegionIdOrders200Ok
+}
+
+type contractSnapshot struct {
+	cycle     uint64
+	contracts []FullContract
+}
+
+// snapshotOrders of a region as the cycle left them. Workers take the
+// snapshot before broadcasting the cycle, so a dump that reads it holds at
+// least every broadcast before it. Returns the number of orders.
+func (s *MarketWatch) snapshotOrders(regionID int64, cycle uint64) int {
+	var orders []esi.GetMarketsRegionIdOrders200Ok
+	s.getMarketStore(regionID).Range(
+		func(k, v interface{}) bool {
+			orders = append(orders, v.(Order).Order)
+			return true
+		},
+	)
+	s.orderSnapshots.Store(regionID, &orderSnapshot{cycle: cycle, orders: orders})
+	return len(orders)
+}
+
+// snapshotContracts of a region as the cycle left them, returning the
+// number of contracts
+func (s *MarketWatch) snapshotContracts(regionID int64, cycle uint64) int {
+	var contracts []FullContract
+	s.getContractStore(regionID).Range(
+		func(k, v interface{}) bool {
+			contracts = append(contracts, v.(Contract).Contract)
+			return true
+		},
+	)
+	s.contractSnapshots.Store(regionID, &contractSnapshot{cycle: cycle, contracts: contracts})
+	return len(contracts)
+}
+
+// dumpMarket sends a new client the region snapshots in chunks, between
+// snapshotBegin and snapshotEnd. It runs in the client's own goroutine.
+func (s *MarketWatch) dumpMarket(channels map[string]bool, send chan interface{}) {
+	if !channels["market"] && !channels["contract"] {
+		return
+	}
+
+	// Take the snapshots up front so that begin and end describe the dump
+	var regions []SnapshotRegion
+	orders := make(map[int64]*orderSnapshot)
+	contracts := make(map[int64]*contractSnapshot)
+	if channels["market"] {
+		s.orderSnapshots.Range(
+			func(k, v interface{}) bool {
+				snapshot := v.(*orderSnapshot)
+				orders[k.(int64)] = snapshot
+				regions = append(
+					regions, SnapshotRegion{
+						RegionID: k.(int64),
+						Channel:  "market",
+						Cycle:    snapshot.cycle,
+						Count:    len(snapshot.orders),
+					},
+				)
+				return true
+			},
+		)
+	}
+	if channels["contract"] {
+		s.contractSnapshots.Range(
+			func(k, v interface{}) bool {
+				snapshot := v.(*contractSnapshot)
+				contracts[k.(int64)] = snapshot
+				regions = append(
+					regions, SnapshotRegion{
+						RegionID: k.(int64),
+						Channel:  "contract",
+						Cycle:    snapshot.cycle,
+						Count:    len(snapshot.contracts),
+					},
+				)
+				return true
+			},
+		)
+	}
+	sort.Slice(
+		regions, func(i, j int) bool {
+			if regions[i].Channel != regions[j].Channel {
+				return regions[i].Channel > regions[j].Channel
+			}
+			return regions[i].RegionID < regions[j].RegionID
+		},
+	)
+
+	send <- Message{Action: "snapshotBegin", Payload: regions}
+	for _, r := range regions {
+		switch r.Channel {
+		case "market":
+			for _, chunk := range chunks(orders[r.RegionID].orders, dumpChunkSize) {
+				chunk := chunk
+				send <- enrichedMessage(
+					Message{Action: "addition", Payload: chunk, RegionID: r.RegionID, Cycle: r.Cycle},
+					func() interface{} {
+						return s.enrichOrders(chunk)
+					},
+				)
+			}
+		case "contract":
+			for _, chunk := range chunks(contracts[r.RegionID].contracts, dumpChunkSize) {
+				chunk := chunk
+				send <- enrichedMessage(
+					Message{Action: "contractAddition", Payload: chunk, RegionID: r.RegionID, Cycle: r.Cycle},
+					func() interface{} {
+						return s.enrichContracts(chunk)
+					},
+				)
+			}
+		}
+	}
+	send <- Message{Action: "snapshotEnd", Payload: regions}
+}
+
+// chunks of a list no longer than size
+func chunks[T any](list []T, size int) [][]T {
+	var split [][]T
+	for len(list) > size {
+		split = append(split, list[:size:size])
+		list = list[size:]
+	}
+	if len(list) > 0 {
+		split = append(split, list)
+	}
+	return split
+}
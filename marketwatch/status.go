@@ -0,0 +1,117 @@
+package marketwatch
+
+import (
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/getsentry/sentry-go"
+)
+
+const (
+	// How often the status channel gets a heartbeat
+	heartbeatInterval = 30 * time.Second
+
+	// Failed cycles are started over after a backoff doubling up to the max
+	failureMinBackoff = 5 * time.Second
+	failureMaxBackoff = 5 * time.Minute
+)
+
+func newCycleStatus(regionID int64, channel string, start time.Time) CycleStatus {
+	return CycleStatus{
+		RegionID: regionID,
+		Channel:  channel,
+		Started:  start,
+	}
+}
+
+// cycleComplete broadcasts a finished cycle
+func (s *MarketWatch) cycleComplete(status CycleStatus) {
+	status.Duration = float64(time.Since(status.Started).Nanoseconds()) / float64(time.Millisecond)
+	s.health.complete(status)
+	s.log.Info(
+		"cycle complete",
+		logging.Channel, status.Channel, logging.RegionID, status.RegionID, logging.CycleID, status.Cycle,
+		"pages", status.Pages, "additions", status.Additions, "changes", status.Changes, "deletions", status.Deletions,
+		"duration_ms", status.Duration,
+	)
+	s.broadcast.Broadcast(
+		"status", Message{
+			Action:   "cycleComplete",
+			Payload:  status,
+			RegionID: status.RegionID,
+			Cycle:    status.Cycle,
+		},
+	)
+}
+
+// cycleFailed broadcasts a cycle given up on, to be started over after a
+// backoff for the number of failures in a row, which it returns
+func (s *MarketWatch) cycleFailed(status CycleStatus, failures int, errs ...error) time.Duration {
+	wait := failureBackoff(failures)
+	status.Duration = float64(time.Since(status.Started).Nanoseconds()) / float64(time.Millisecond)
+	status.NextRun = time.Now().Add(wait)
+	for _, err := range errs {
+		status.Errors = append(status.Errors, err.Error())
+	}
+	s.log.Warn(
+		"cycle failed",
+		logging.Channel, status.Channel, logging.RegionID, status.RegionID, "errors", len(errs),
+		"duration_ms", status.Duration, "retry_in", wait.String(),
+	)
+	s.broadcast.Broadcast(
+		"status", Message{
+			Action:   "cycleFailed",
+			Payload:  status,
+			RegionID: status.RegionID,
+		},
+	)
+	return wait
+}
+
+// cycleDelayed broadcasts a cycle put off until the cache window turns
+func (s *MarketWatch) cycleDelayed(status CycleStatus, wait time.Duration) {
+	status.NextRun = time.Now().Add(wait)
+	s.log.Info(
+		"too close to the cache window, waiting",
+		logging.Channel, status.Channel, logging.RegionID, status.RegionID, "wait", wait.String(),
+	)
+	s.broadcast.Broadcast(
+		"status", Message{
+			Action:   "cycleDelayed",
+			Payload:  status,
+			RegionID: status.RegionID,
+		},
+	)
+}
+
+// failureBackoff before starting over after failures in a row
+func failureBackoff(failures int) time.Duration {
+	wait := failureMinBackoff
+	for i := 1; i < failures && wait < failureMaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > failureMaxBackoff {
+		return failureMaxBackoff
+	}
+	return wait
+}
+
+// heartbeat broadcasts the server time on the status channel
+func (s *MarketWatch) heartbeat(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#status-heartbeat")
+		},
+	)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for t := range ticker.C {
+		s.broadcast.Broadcast(
+			"status", Message{
+				Action:  "heartbeat",
+				Payload: Heartbeat{ServerTime: t.UTC()},
+			},
+		)
+	}
+}
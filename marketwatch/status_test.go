@@ -0,0 +1,16 @@
+package marketwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, failureBackoff(1))
+	assert.Equal(t, 10*time.Second, failureBackoff(2))
+	assert.Equal(t, 40*time.Second, failureBackoff(4))
+	assert.Equal(t, failureMaxBackoff, failureBackoff(7))
+	assert.Equal(t, failureMaxBackoff, failureBackoff(1000))
+}
@@ -0,0 +1,243 @@
+package marketwatch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/sde"
+	"github.com/contorno/goesi"
+	"github.com/getsentry/sentry-go"
+)
+
+const (
+	// Upper bound of NPC station IDs
+	maxStationID = 64000000
+
+	// Failed ESI lookups are asked again after this long
+	lookupRetry = 5 * time.Minute
+
+	// ESI lookups waiting for the resolver, more are asked for again later
+	lookupQueue = 1000
+)
+
+// universeCache resolves names from the static data and what was looked up
+// in ESI. It never waits on ESI: names it does not know are looked up in the
+// background by resolve and show up once they are in. ESI answers are kept
+// for the life of the process, failures only for lookupRetry so a passing
+// ESI error does not leave a name blank.
+type universeCache struct {
+	static *sde.Store
+	esi    *goesi.APIClient
+
+	types     sync.Map // typeID -> lookup[string]
+	locations sync.Map // locationID -> lookup[locationName]
+	systems   sync.Map // systemID -> lookup[sde.Location]
+
+	// lookups for the resolver, and those queued so they are asked once
+	lookups chan interface{}
+	queued  sync.Map
+}
+
+// ESI lookups for the resolver
+type (
+	typeLookup    int32
+	stationLookup int32
+	systemLookup  int32
+)
+
+// lookup kept in the cache, with what is known of a failed one
+type lookup[T any] struct {
+	value T
+
+	// when to ask again, zero once resolved
+	retryAt time.Time
+}
+
+func resolved[T any](v T) lookup[T] {
+	return lookup[T]{value: v}
+}
+
+func failed[T any](v T) lookup[T] {
+	return lookup[T]{value: v, retryAt: time.Now().Add(lookupRetry)}
+}
+
+// cached value of a key, unless it is a failure due to be asked again
+func cached[T any](m *sync.Map, key interface{}) (T, bool) {
+	v, ok := m.Load(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	l := v.(lookup[T])
+	if !l.retryAt.IsZero() && time.Now().After(l.retryAt) {
+		return l.value, false
+	}
+	return l.value, true
+}
+
+type locationName struct {
+	Name     string
+	SystemID int32
+}
+
+func newUniverseCache(static *sde.Store, esi *goesi.APIClient) *universeCache {
+	return &universeCache{
+		static:  static,
+		esi:     esi,
+		lookups: make(chan interface{}, lookupQueue),
+	}
+}
+
+// request a lookup from the resolver unless it is queued already. When the
+// queue is full it is left for a later request.
+func (u *universeCache) request(l interface{}) {
+	if _, queued := u.queued.LoadOrStore(l, true); queued {
+		return
+	}
+	select {
+	case u.lookups <- l:
+	default:
+		u.queued.Delete(l)
+	}
+}
+
+// resolve requested lookups from ESI
+func (u *universeCache) resolve(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#universe-resolve")
+		},
+	)
+
+	for l := range u.lookups {
+		switch id := l.(type) {
+		case typeLookup:
+			u.resolveType(int32(id))
+		case stationLookup:
+			u.resolveStation(int32(id))
+		case systemLookup:
+			u.resolveSystem(int32(id))
+		}
+		u.queued.Delete(l)
+	}
+}
+
+// typeName of an item type, empty until it is known
+func (u *universeCache) typeName(typeID int32) string {
+	if d := u.static.Data(); d != nil {
+		if t, ok := d.Types[typeID]; ok {
+			return t.Name
+		}
+	}
+	name, ok := cached[string](&u.types, typeID)
+	if !ok {
+		u.request(typeLookup(typeID))
+	}
+	return name
+}
+
+func (u *universeCache) resolveType(typeID int32) {
+	t, _, err := u.esi.ESI.UniverseApi.GetUniverseTypesTypeId(context.Background(), typeID, nil)
+	if err != nil {
+		u.types.Store(typeID, failed(""))
+		return
+	}
+	u.types.Store(typeID, resolved(t.Name))
+}
+
+// location of a station, structure or solar system. The system hint is used
+// when the location is not known, as with structures: ESI only names those
+// to characters with access, so they are placed by their hint alone.
+func (u *universeCache) location(locationID int64, systemHint int32) sde.Location {
+	if d := u.static.Data(); d != nil {
+		if loc, ok := d.Location(locationID); ok {
+			return loc
+		}
+	}
+
+	name, ok := cached[locationName](&u.locations, locationID)
+	if !ok {
+		if locationID < maxStationID {
+			u.request(stationLookup(locationID))
+		} else if systemHint != 0 {
+			name = locationName{SystemID: systemHint}
+			u.locations.Store(locationID, resolved(name))
+		}
+	}
+	if name.SystemID == 0 {
+		name.SystemID = systemHint
+	}
+	if name.SystemID == 0 {
+		return sde.Location{StationID: locationID}
+	}
+
+	loc := u.system(name.SystemID)
+	loc.StationID = locationID
+	loc.StationName = name.Name
+	return loc
+}
+
+func (u *universeCache) resolveStation(stationID int32) {
+	station, _, err := u.esi.ESI.UniverseApi.GetUniverseStationsStationId(context.Background(), stationID, nil)
+	if err != nil {
+		u.locations.Store(int64(stationID), failed(locationName{}))
+		return
+	}
+	u.locations.Store(int64(stationID), resolved(locationName{Name: station.Name, SystemID: station.SystemId}))
+}
+
+// system placed in the universe hierarchy, only its ID until it is known
+func (u *universeCache) system(systemID int32) sde.Location {
+	if d := u.static.Data(); d != nil {
+		if loc, ok := d.SystemLocation(systemID); ok {
+			return loc
+		}
+	}
+	loc, ok := cached[sde.Location](&u.systems, systemID)
+	if !ok {
+		u.request(systemLookup(systemID))
+	}
+	if loc.SolarSystemID == 0 {
+		loc.SolarSystemID = systemID
+	}
+	return loc
+}
+
+func (u *universeCache) resolveSystem(systemID int32) {
+	loc, err := u.systemFromESI(systemID)
+	if err != nil {
+		u.systems.Store(systemID, failed(loc))
+		return
+	}
+	u.systems.Store(systemID, resolved(loc))
+}
+
+// systemFromESI with as much of the hierarchy as could be looked up
+func (u *universeCache) systemFromESI(systemID int32) (sde.Location, error) {
+	loc := sde.Location{SolarSystemID: systemID}
+	ctx := context.Background()
+	system, _, err := u.esi.ESI.UniverseApi.GetUniverseSystemsSystemId(ctx, systemID, nil)
+	if err != nil {
+		return loc, err
+	}
+	loc.SolarSystemName = system.Name
+	loc.Security = float64(system.SecurityStatus)
+	loc.ConstellationID = system.ConstellationId
+
+	constellation, _, err := u.esi.ESI.UniverseApi.GetUniverseConstellationsConstellationId(
+		ctx, system.ConstellationId, nil,
+	)
+	if err != nil {
+		return loc, err
+	}
+	loc.ConstellationName = constellation.Name
+	loc.RegionID = constellation.RegionId
+
+	region, _, err := u.esi.ESI.UniverseApi.GetUniverseRegionsRegionId(ctx, constellation.RegionId, nil)
+	if err != nil {
+		return loc, err
+	}
+	loc.RegionName = region.Name
+	return loc, nil
+}
@@ -0,0 +1,99 @@
+package marketwatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/sde"
+	"github.com/contorno/goesi"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// testESI answers type lookups, failing while failing is set
+func testESI(t *testing.T, requests, failing *int32) *goesi.APIClient {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(requests, 1)
+				if atomic.LoadInt32(failing) == 1 {
+					http.Error(w, "down", http.StatusBadGateway)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"type_id": 34, "name": "Tritanium"}`))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	client := goesi.NewAPIClient(server.Client(), "test")
+	client.ChangeBasePath(server.URL)
+	return client
+}
+
+func TestUniverseRetriesFailures(t *testing.T) {
+	var requests, failing int32 = 0, 1
+	u := newUniverseCache(nil, testESI(t, &requests, &failing))
+
+	// Looked up in the background, and once only while queued
+	assert.Equal(t, "", u.typeName(34))
+	assert.Equal(t, "", u.typeName(34))
+	assert.Len(t, u.lookups, 1)
+	go u.resolve(sentry.CurrentHub().Clone())
+	assert.Eventually(
+		t, func() bool {
+			_, ok := u.types.Load(int32(34))
+			return ok
+		}, time.Second, time.Millisecond,
+	)
+
+	// A failure is not asked again right away
+	assert.Equal(t, "", u.typeName(34))
+	assert.Len(t, u.lookups, 0)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// but once it is due
+	atomic.StoreInt32(&failing, 0)
+	v, _ := u.types.Load(int32(34))
+	l := v.(lookup[string])
+	l.retryAt = time.Now().Add(-time.Second)
+	u.types.Store(int32(34), l)
+	assert.Equal(t, "", u.typeName(34))
+	assert.Eventually(
+		t, func() bool {
+			return u.typeName(34) == "Tritanium"
+		}, time.Second, time.Millisecond,
+	)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// and kept once resolved
+	assert.Equal(t, "Tritanium", u.typeName(34))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestUniverseStructures(t *testing.T) {
+	var requests, failing int32
+	static, err := sde.NewStore("../sde/testdata")
+	assert.Nil(t, err)
+	u := newUniverseCache(static, testESI(t, &requests, &failing))
+
+	// Placed by the system hint and never asked of ESI
+	loc := u.location(1035466617946, 30000142)
+	assert.Equal(t, int64(1035466617946), loc.StationID)
+	assert.Equal(t, "Jita", loc.SolarSystemName)
+	assert.Equal(t, "The Forge", loc.RegionName)
+	assert.Len(t, u.lookups, 0)
+
+	// The hint is kept for payloads without a system
+	assert.Equal(t, "Jita", u.location(1035466617946, 0).SolarSystemName)
+	assert.Equal(t, sde.Location{StationID: 1035466617947}, u.location(1035466617947, 0))
+	assert.Len(t, u.lookups, 0)
+
+	// NPC stations missing from the static data are looked up
+	assert.Equal(t, "Jita", u.location(60000001, 30000142).SolarSystemName)
+	assert.Len(t, u.lookups, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"math/big"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
@@ -35,3 +36,11 @@ func sleepRandom(max int64, additional float64) {
 	nBig, _ := rand.Int(rand.Reader, big.NewInt(max*10))
 	time.Sleep(time.Duration(additional+float64(nBig.Int64())/10) * time.Second)
 }
+
+// envOr reads an environment variable with a default
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
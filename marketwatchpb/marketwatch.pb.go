@@ -0,0 +1,2112 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: marketwatch/v1/marketwatch.proto
+
+package marketwatchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SubscribeRequest filters the stream. Empty fields match everything.
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// market, contract or deals
+	Channels []string `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+	// addition, change, deletion, contractAddition, contractChange,
+	// contractDeletion or deal
+	Actions []string `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
+	// order types, or types of items in contracts
+	TypeIds []int32 `protobuf:"varint,3,rep,packed,name=type_ids,json=typeIds,proto3" json:"type_ids,omitempty"`
+	// order or contract start locations
+	LocationIds []int64 `protobuf:"varint,4,rep,packed,name=location_ids,json=locationIds,proto3" json:"location_ids,omitempty"`
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubscribeRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetTypeIds() []int32 {
+	if x != nil {
+		return x.TypeIds
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetLocationIds() []int64 {
+	if x != nil {
+		return x.LocationIds
+	}
+	return nil
+}
+
+// Event is one broadcast, holding only the entries that passed the filters
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Action  string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// Types that are assignable to Payload:
+	//	*Event_Orders
+	//	*Event_OrderChanges
+	//	*Event_Contracts
+	//	*Event_ContractChanges
+	Payload isEvent_Payload `protobuf_oneof:"payload"`
+	// Region and cycle that produced the payload
+	RegionId int64  `protobuf:"varint,7,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Cycle    uint64 `protobuf:"varint,8,opt,name=cycle,proto3" json:"cycle,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Event) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *Event) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (m *Event) GetPayload() isEvent_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetOrders() *Orders {
+	if x, ok := x.GetPayload().(*Event_Orders); ok {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *Event) GetOrderChanges() *OrderChanges {
+	if x, ok := x.GetPayload().(*Event_OrderChanges); ok {
+		return x.OrderChanges
+	}
+	return nil
+}
+
+func (x *Event) GetContracts() *Contracts {
+	if x, ok := x.GetPayload().(*Event_Contracts); ok {
+		return x.Contracts
+	}
+	return nil
+}
+
+func (x *Event) GetContractChanges() *ContractChanges {
+	if x, ok := x.GetPayload().(*Event_ContractChanges); ok {
+		return x.ContractChanges
+	}
+	return nil
+}
+
+func (x *Event) GetRegionId() int64 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *Event) GetCycle() uint64 {
+	if x != nil {
+		return x.Cycle
+	}
+	return 0
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Orders struct {
+	Orders *Orders `protobuf:"bytes,3,opt,name=orders,proto3,oneof"`
+}
+
+type Event_OrderChanges struct {
+	OrderChanges *OrderChanges `protobuf:"bytes,4,opt,name=order_changes,json=orderChanges,proto3,oneof"`
+}
+
+type Event_Contracts struct {
+	Contracts *Contracts `protobuf:"bytes,5,opt,name=contracts,proto3,oneof"`
+}
+
+type Event_ContractChanges struct {
+	ContractChanges *ContractChanges `protobuf:"bytes,6,opt,name=contract_changes,json=contractChanges,proto3,oneof"`
+}
+
+func (*Event_Orders) isEvent_Payload() {}
+
+func (*Event_OrderChanges) isEvent_Payload() {}
+
+func (*Event_Contracts) isEvent_Payload() {}
+
+func (*Event_ContractChanges) isEvent_Payload() {}
+
+type GetOrdersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RegionId   int64 `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	TypeId     int32 `protobuf:"varint,2,opt,name=type_id,json=typeId,proto3" json:"type_id,omitempty"`
+	LocationId int64 `protobuf:"varint,3,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+}
+
+func (x *GetOrdersRequest) Reset() {
+	*x = GetOrdersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrdersRequest) ProtoMessage() {}
+
+func (x *GetOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrdersRequest.ProtoReflect.Descriptor instead.
+func (*GetOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetOrdersRequest) GetRegionId() int64 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *GetOrdersRequest) GetTypeId() int32 {
+	if x != nil {
+		return x.TypeId
+	}
+	return 0
+}
+
+func (x *GetOrdersRequest) GetLocationId() int64 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+type GetContractsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RegionId   int64 `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	TypeId     int32 `protobuf:"varint,2,opt,name=type_id,json=typeId,proto3" json:"type_id,omitempty"`
+	LocationId int64 `protobuf:"varint,3,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+	// item_exchange, auction or courier
+	Type string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+}
+
+func (x *GetContractsRequest) Reset() {
+	*x = GetContractsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetContractsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContractsRequest) ProtoMessage() {}
+
+func (x *GetContractsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContractsRequest.ProtoReflect.Descriptor instead.
+func (*GetContractsRequest) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetContractsRequest) GetRegionId() int64 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *GetContractsRequest) GetTypeId() int32 {
+	if x != nil {
+		return x.TypeId
+	}
+	return 0
+}
+
+func (x *GetContractsRequest) GetLocationId() int64 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+func (x *GetContractsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+// Order is an ESI market order
+type Order struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OrderId      int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Duration     int32                  `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	IsBuyOrder   bool                   `protobuf:"varint,3,opt,name=is_buy_order,json=isBuyOrder,proto3" json:"is_buy_order,omitempty"`
+	Issued       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=issued,proto3" json:"issued,omitempty"`
+	LocationId   int64                  `protobuf:"varint,5,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+	MinVolume    int32                  `protobuf:"varint,6,opt,name=min_volume,json=minVolume,proto3" json:"min_volume,omitempty"`
+	Price        float64                `protobuf:"fixed64,7,opt,name=price,proto3" json:"price,omitempty"`
+	Range        string                 `protobuf:"bytes,8,opt,name=range,proto3" json:"range,omitempty"`
+	SystemId     int32                  `protobuf:"varint,9,opt,name=system_id,json=systemId,proto3" json:"system_id,omitempty"`
+	TypeId       int32                  `protobuf:"varint,10,opt,name=type_id,json=typeId,proto3" json:"type_id,omitempty"`
+	VolumeRemain int32                  `protobuf:"varint,11,opt,name=volume_remain,json=volumeRemain,proto3" json:"volume_remain,omitempty"`
+	VolumeTotal  int32                  `protobuf:"varint,12,opt,name=volume_total,json=volumeTotal,proto3" json:"volume_total,omitempty"`
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Order) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Order) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *Order) GetIsBuyOrder() bool {
+	if x != nil {
+		return x.IsBuyOrder
+	}
+	return false
+}
+
+func (x *Order) GetIssued() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Issued
+	}
+	return nil
+}
+
+func (x *Order) GetLocationId() int64 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+func (x *Order) GetMinVolume() int32 {
+	if x != nil {
+		return x.MinVolume
+	}
+	return 0
+}
+
+func (x *Order) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Order) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *Order) GetSystemId() int32 {
+	if x != nil {
+		return x.SystemId
+	}
+	return 0
+}
+
+func (x *Order) GetTypeId() int32 {
+	if x != nil {
+		return x.TypeId
+	}
+	return 0
+}
+
+func (x *Order) GetVolumeRemain() int32 {
+	if x != nil {
+		return x.VolumeRemain
+	}
+	return 0
+}
+
+func (x *Order) GetVolumeTotal() int32 {
+	if x != nil {
+		return x.VolumeTotal
+	}
+	return 0
+}
+
+type Orders struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Orders []*Order `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+}
+
+func (x *Orders) Reset() {
+	*x = Orders{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Orders) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Orders) ProtoMessage() {}
+
+func (x *Orders) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Orders.ProtoReflect.Descriptor instead.
+func (*Orders) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Orders) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+// OrderChange is a change or deletion of an order
+type OrderChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OrderId      int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	LocationId   int64                  `protobuf:"varint,2,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+	TypeId       int32                  `protobuf:"varint,3,opt,name=type_id,json=typeId,proto3" json:"type_id,omitempty"`
+	VolumeChange int32                  `protobuf:"varint,4,opt,name=volume_change,json=volumeChange,proto3" json:"volume_change,omitempty"`
+	VolumeRemain int32                  `protobuf:"varint,5,opt,name=volume_remain,json=volumeRemain,proto3" json:"volume_remain,omitempty"`
+	Price        float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`
+	Duration     int32                  `protobuf:"varint,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	IsBuyOrder   bool                   `protobuf:"varint,8,opt,name=is_buy_order,json=isBuyOrder,proto3" json:"is_buy_order,omitempty"`
+	Issued       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=issued,proto3" json:"issued,omitempty"`
+	TimeChanged  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=time_changed,json=timeChanged,proto3" json:"time_changed,omitempty"`
+}
+
+func (x *OrderChange) Reset() {
+	*x = OrderChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OrderChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderChange) ProtoMessage() {}
+
+func (x *OrderChange) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderChange.ProtoReflect.Descriptor instead.
+func (*OrderChange) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OrderChange) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderChange) GetLocationId() int64 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+func (x *OrderChange) GetTypeId() int32 {
+	if x != nil {
+		return x.TypeId
+	}
+	return 0
+}
+
+func (x *OrderChange) GetVolumeChange() int32 {
+	if x != nil {
+		return x.VolumeChange
+	}
+	return 0
+}
+
+func (x *OrderChange) GetVolumeRemain() int32 {
+	if x != nil {
+		return x.VolumeRemain
+	}
+	return 0
+}
+
+func (x *OrderChange) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderChange) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *OrderChange) GetIsBuyOrder() bool {
+	if x != nil {
+		return x.IsBuyOrder
+	}
+	return false
+}
+
+func (x *OrderChange) GetIssued() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Issued
+	}
+	return nil
+}
+
+func (x *OrderChange) GetTimeChanged() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TimeChanged
+	}
+	return nil
+}
+
+type OrderChanges struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Changes []*OrderChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *OrderChanges) Reset() {
+	*x = OrderChanges{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OrderChanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderChanges) ProtoMessage() {}
+
+func (x *OrderChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderChanges.ProtoReflect.Descriptor instead.
+func (*OrderChanges) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OrderChanges) GetChanges() []*OrderChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// Contract is an ESI public contract
+type Contract struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Buyout              float64                `protobuf:"fixed64,1,opt,name=buyout,proto3" json:"buyout,omitempty"`
+	Collateral          float64                `protobuf:"fixed64,2,opt,name=collateral,proto3" json:"collateral,omitempty"`
+	ContractId          int32                  `protobuf:"varint,3,opt,name=contract_id,json=contractId,proto3" json:"contract_id,omitempty"`
+	DateExpired         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date_expired,json=dateExpired,proto3" json:"date_expired,omitempty"`
+	DateIssued          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=date_issued,json=dateIssued,proto3" json:"date_issued,omitempty"`
+	DaysToComplete      int32                  `protobuf:"varint,6,opt,name=days_to_complete,json=daysToComplete,proto3" json:"days_to_complete,omitempty"`
+	EndLocationId       int64                  `protobuf:"varint,7,opt,name=end_location_id,json=endLocationId,proto3" json:"end_location_id,omitempty"`
+	ForCorporation      bool                   `protobuf:"varint,8,opt,name=for_corporation,json=forCorporation,proto3" json:"for_corporation,omitempty"`
+	IssuerCorporationId int32                  `protobuf:"varint,9,opt,name=issuer_corporation_id,json=issuerCorporationId,proto3" json:"issuer_corporation_id,omitempty"`
+	IssuerId            int32                  `protobuf:"varint,10,opt,name=issuer_id,json=issuerId,proto3" json:"issuer_id,omitempty"`
+	Price               float64                `protobuf:"fixed64,11,opt,name=price,proto3" json:"price,omitempty"`
+	Reward              float64                `protobuf:"fixed64,12,opt,name=reward,proto3" json:"reward,omitempty"`
+	StartLocationId     int64                  `protobuf:"varint,13,opt,name=start_location_id,json=startLocationId,proto3" json:"start_location_id,omitempty"`
+	Title               string                 `protobuf:"bytes,14,opt,name=title,proto3" json:"title,omitempty"`
+	Type                string                 `protobuf:"bytes,15,opt,name=type,proto3" json:"type,omitempty"`
+	Volume              float64                `protobuf:"fixed64,16,opt,name=volume,proto3" json:"volume,omitempty"`
+}
+
+func (x *Contract) Reset() {
+	*x = Contract{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Contract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contract) ProtoMessage() {}
+
+func (x *Contract) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contract.ProtoReflect.Descriptor instead.
+func (*Contract) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Contract) GetBuyout() float64 {
+	if x != nil {
+		return x.Buyout
+	}
+	return 0
+}
+
+func (x *Contract) GetCollateral() float64 {
+	if x != nil {
+		return x.Collateral
+	}
+	return 0
+}
+
+func (x *Contract) GetContractId() int32 {
+	if x != nil {
+		return x.ContractId
+	}
+	return 0
+}
+
+func (x *Contract) GetDateExpired() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateExpired
+	}
+	return nil
+}
+
+func (x *Contract) GetDateIssued() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateIssued
+	}
+	return nil
+}
+
+func (x *Contract) GetDaysToComplete() int32 {
+	if x != nil {
+		return x.DaysToComplete
+	}
+	return 0
+}
+
+func (x *Contract) GetEndLocationId() int64 {
+	if x != nil {
+		return x.EndLocationId
+	}
+	return 0
+}
+
+func (x *Contract) GetForCorporation() bool {
+	if x != nil {
+		return x.ForCorporation
+	}
+	return false
+}
+
+func (x *Contract) GetIssuerCorporationId() int32 {
+	if x != nil {
+		return x.IssuerCorporationId
+	}
+	return 0
+}
+
+func (x *Contract) GetIssuerId() int32 {
+	if x != nil {
+		return x.IssuerId
+	}
+	return 0
+}
+
+func (x *Contract) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Contract) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *Contract) GetStartLocationId() int64 {
+	if x != nil {
+		return x.StartLocationId
+	}
+	return 0
+}
+
+func (x *Contract) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Contract) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Contract) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+// ContractItem is an item in a contract
+type ContractItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsBlueprintCopy    bool  `protobuf:"varint,1,opt,name=is_blueprint_copy,json=isBlueprintCopy,proto3" json:"is_blueprint_copy,omitempty"`
+	IsIncluded         bool  `protobuf:"varint,2,opt,name=is_included,json=isIncluded,proto3" json:"is_included,omitempty"`
+	ItemId             int64 `protobuf:"varint,3,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	MaterialEfficiency int32 `protobuf:"varint,4,opt,name=material_efficiency,json=materialEfficiency,proto3" json:"material_efficiency,omitempty"`
+	Quantity           int32 `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	RecordId           int64 `protobuf:"varint,6,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Runs               int32 `protobuf:"varint,7,opt,name=runs,proto3" json:"runs,omitempty"`
+	TimeEfficiency     int32 `protobuf:"varint,8,opt,name=time_efficiency,json=timeEfficiency,proto3" json:"time_efficiency,omitempty"`
+	TypeId             int32 `protobuf:"varint,9,opt,name=type_id,json=typeId,proto3" json:"type_id,omitempty"`
+}
+
+func (x *ContractItem) Reset() {
+	*x = ContractItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContractItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContractItem) ProtoMessage() {}
+
+func (x *ContractItem) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContractItem.ProtoReflect.Descriptor instead.
+func (*ContractItem) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ContractItem) GetIsBlueprintCopy() bool {
+	if x != nil {
+		return x.IsBlueprintCopy
+	}
+	return false
+}
+
+func (x *ContractItem) GetIsIncluded() bool {
+	if x != nil {
+		return x.IsIncluded
+	}
+	return false
+}
+
+func (x *ContractItem) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *ContractItem) GetMaterialEfficiency() int32 {
+	if x != nil {
+		return x.MaterialEfficiency
+	}
+	return 0
+}
+
+func (x *ContractItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ContractItem) GetRecordId() int64 {
+	if x != nil {
+		return x.RecordId
+	}
+	return 0
+}
+
+func (x *ContractItem) GetRuns() int32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *ContractItem) GetTimeEfficiency() int32 {
+	if x != nil {
+		return x.TimeEfficiency
+	}
+	return 0
+}
+
+func (x *ContractItem) GetTypeId() int32 {
+	if x != nil {
+		return x.TypeId
+	}
+	return 0
+}
+
+// ContractBid is a bid on an auction
+type ContractBid struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Amount  float32                `protobuf:"fixed32,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	BidId   int32                  `protobuf:"varint,2,opt,name=bid_id,json=bidId,proto3" json:"bid_id,omitempty"`
+	DateBid *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=date_bid,json=dateBid,proto3" json:"date_bid,omitempty"`
+}
+
+func (x *ContractBid) Reset() {
+	*x = ContractBid{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContractBid) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContractBid) ProtoMessage() {}
+
+func (x *ContractBid) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContractBid.ProtoReflect.Descriptor instead.
+func (*ContractBid) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ContractBid) GetAmount() float32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ContractBid) GetBidId() int32 {
+	if x != nil {
+		return x.BidId
+	}
+	return 0
+}
+
+func (x *ContractBid) GetDateBid() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateBid
+	}
+	return nil
+}
+
+// CourierRoute is the shortest stargate route of a courier contract
+type CourierRoute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartSystemId int32 `protobuf:"varint,1,opt,name=start_system_id,json=startSystemId,proto3" json:"start_system_id,omitempty"`
+	EndSystemId   int32 `protobuf:"varint,2,opt,name=end_system_id,json=endSystemId,proto3" json:"end_system_id,omitempty"`
+	Jumps         int32 `protobuf:"varint,3,opt,name=jumps,proto3" json:"jumps,omitempty"`
+	HighSec       int32 `protobuf:"varint,4,opt,name=high_sec,json=highSec,proto3" json:"high_sec,omitempty"`
+	LowSec        int32 `protobuf:"varint,5,opt,name=low_sec,json=lowSec,proto3" json:"low_sec,omitempty"`
+	NullSec       int32 `protobuf:"varint,6,opt,name=null_sec,json=nullSec,proto3" json:"null_sec,omitempty"`
+}
+
+func (x *CourierRoute) Reset() {
+	*x = CourierRoute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CourierRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CourierRoute) ProtoMessage() {}
+
+func (x *CourierRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CourierRoute.ProtoReflect.Descriptor instead.
+func (*CourierRoute) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CourierRoute) GetStartSystemId() int32 {
+	if x != nil {
+		return x.StartSystemId
+	}
+	return 0
+}
+
+func (x *CourierRoute) GetEndSystemId() int32 {
+	if x != nil {
+		return x.EndSystemId
+	}
+	return 0
+}
+
+func (x *CourierRoute) GetJumps() int32 {
+	if x != nil {
+		return x.Jumps
+	}
+	return 0
+}
+
+func (x *CourierRoute) GetHighSec() int32 {
+	if x != nil {
+		return x.HighSec
+	}
+	return 0
+}
+
+func (x *CourierRoute) GetLowSec() int32 {
+	if x != nil {
+		return x.LowSec
+	}
+	return 0
+}
+
+func (x *CourierRoute) GetNullSec() int32 {
+	if x != nil {
+		return x.NullSec
+	}
+	return 0
+}
+
+// CourierInfo enriches courier contracts
+type CourierInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Route           *CourierRoute `protobuf:"bytes,1,opt,name=route,proto3" json:"route,omitempty"`
+	IskPerJump      float64       `protobuf:"fixed64,2,opt,name=isk_per_jump,json=iskPerJump,proto3" json:"isk_per_jump,omitempty"`
+	IskPerM3        float64       `protobuf:"fixed64,3,opt,name=isk_per_m3,json=iskPerM3,proto3" json:"isk_per_m3,omitempty"`
+	CollateralRatio float64       `protobuf:"fixed64,4,opt,name=collateral_ratio,json=collateralRatio,proto3" json:"collateral_ratio,omitempty"`
+}
+
+func (x *CourierInfo) Reset() {
+	*x = CourierInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CourierInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CourierInfo) ProtoMessage() {}
+
+func (x *CourierInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CourierInfo.ProtoReflect.Descriptor instead.
+func (*CourierInfo) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CourierInfo) GetRoute() *CourierRoute {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+func (x *CourierInfo) GetIskPerJump() float64 {
+	if x != nil {
+		return x.IskPerJump
+	}
+	return 0
+}
+
+func (x *CourierInfo) GetIskPerM3() float64 {
+	if x != nil {
+		return x.IskPerM3
+	}
+	return 0
+}
+
+func (x *CourierInfo) GetCollateralRatio() float64 {
+	if x != nil {
+		return x.CollateralRatio
+	}
+	return 0
+}
+
+// FullContract is a contract with its items, bids and appraisal
+type FullContract struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Contract       *Contract       `protobuf:"bytes,1,opt,name=contract,proto3" json:"contract,omitempty"`
+	Items          []*ContractItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	Bids           []*ContractBid  `protobuf:"bytes,3,rep,name=bids,proto3" json:"bids,omitempty"`
+	EstimatedValue float64         `protobuf:"fixed64,4,opt,name=estimated_value,json=estimatedValue,proto3" json:"estimated_value,omitempty"`
+	PriceRatio     float64         `protobuf:"fixed64,5,opt,name=price_ratio,json=priceRatio,proto3" json:"price_ratio,omitempty"`
+	Courier        *CourierInfo    `protobuf:"bytes,6,opt,name=courier,proto3" json:"courier,omitempty"`
+}
+
+func (x *FullContract) Reset() {
+	*x = FullContract{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FullContract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FullContract) ProtoMessage() {}
+
+func (x *FullContract) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FullContract.ProtoReflect.Descriptor instead.
+func (*FullContract) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FullContract) GetContract() *Contract {
+	if x != nil {
+		return x.Contract
+	}
+	return nil
+}
+
+func (x *FullContract) GetItems() []*ContractItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *FullContract) GetBids() []*ContractBid {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *FullContract) GetEstimatedValue() float64 {
+	if x != nil {
+		return x.EstimatedValue
+	}
+	return 0
+}
+
+func (x *FullContract) GetPriceRatio() float64 {
+	if x != nil {
+		return x.PriceRatio
+	}
+	return 0
+}
+
+func (x *FullContract) GetCourier() *CourierInfo {
+	if x != nil {
+		return x.Courier
+	}
+	return nil
+}
+
+type Contracts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Contracts []*FullContract `protobuf:"bytes,1,rep,name=contracts,proto3" json:"contracts,omitempty"`
+}
+
+func (x *Contracts) Reset() {
+	*x = Contracts{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Contracts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contracts) ProtoMessage() {}
+
+func (x *Contracts) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contracts.ProtoReflect.Descriptor instead.
+func (*Contracts) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Contracts) GetContracts() []*FullContract {
+	if x != nil {
+		return x.Contracts
+	}
+	return nil
+}
+
+// ContractChange is a change or deletion of a contract
+type ContractChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContractId int32 `protobuf:"varint,1,opt,name=contract_id,json=contractId,proto3" json:"contract_id,omitempty"`
+	LocationId int64 `protobuf:"varint,2,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`
+	Expired    bool  `protobuf:"varint,3,opt,name=expired,proto3" json:"expired,omitempty"`
+	// accepted, expired or removed, deletions only
+	Reason      string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	DateExpired *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=date_expired,json=dateExpired,proto3" json:"date_expired,omitempty"`
+	Bids        []*ContractBid         `protobuf:"bytes,6,rep,name=bids,proto3" json:"bids,omitempty"`
+	Items       []*ContractItem        `protobuf:"bytes,7,rep,name=items,proto3" json:"items,omitempty"`
+	Price       float64                `protobuf:"fixed64,8,opt,name=price,proto3" json:"price,omitempty"`
+	Type        string                 `protobuf:"bytes,9,opt,name=type,proto3" json:"type,omitempty"`
+	TimeChanged *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=time_changed,json=timeChanged,proto3" json:"time_changed,omitempty"`
+}
+
+func (x *ContractChange) Reset() {
+	*x = ContractChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContractChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContractChange) ProtoMessage() {}
+
+func (x *ContractChange) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContractChange.ProtoReflect.Descriptor instead.
+func (*ContractChange) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ContractChange) GetContractId() int32 {
+	if x != nil {
+		return x.ContractId
+	}
+	return 0
+}
+
+func (x *ContractChange) GetLocationId() int64 {
+	if x != nil {
+		return x.LocationId
+	}
+	return 0
+}
+
+func (x *ContractChange) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+func (x *ContractChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ContractChange) GetDateExpired() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateExpired
+	}
+	return nil
+}
+
+func (x *ContractChange) GetBids() []*ContractBid {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *ContractChange) GetItems() []*ContractItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ContractChange) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ContractChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ContractChange) GetTimeChanged() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TimeChanged
+	}
+	return nil
+}
+
+type ContractChanges struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Changes []*ContractChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *ContractChanges) Reset() {
+	*x = ContractChanges{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContractChanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContractChanges) ProtoMessage() {}
+
+func (x *ContractChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_marketwatch_v1_marketwatch_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContractChanges.ProtoReflect.Descriptor instead.
+func (*ContractChanges) Descriptor() ([]byte, []int) {
+	return file_marketwatch_v1_marketwatch_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ContractChanges) GetChanges() []*ContractChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+var File_marketwatch_v1_marketwatch_proto protoreflect.FileDescriptor
+
+var file_marketwatch_v1_marketwatch_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x76, 0x31,
+	0x2f, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x86, 0x01, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x19,
+	0x0a, 0x08, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x07, 0x74, 0x79, 0x70, 0x65, 0x49, 0x64, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x03, 0x52,
+	0x0b, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x73, 0x22, 0xf7, 0x02, 0x0a,
+	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x06, 0x6f, 0x72, 0x64, 0x65,
+	0x72, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65,
+	0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x73,
+	0x48, 0x00, 0x52, 0x06, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x12, 0x43, 0x0a, 0x0d, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x48,
+	0x00, 0x52, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12,
+	0x39, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x48, 0x00, 0x52,
+	0x09, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x12, 0x4c, 0x0a, 0x10, 0x63, 0x6f,
+	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74,
+	0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x67,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x69, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65,
+	0x67, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72,
+	0x65, 0x67, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x79, 0x70, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x79, 0x70, 0x65, 0x49, 0x64,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49,
+	0x64, 0x22, 0x80, 0x01, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x67,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65,
+	0x67, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x79, 0x70, 0x65, 0x49, 0x64, 0x12,
+	0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x22, 0xfe, 0x02, 0x0a, 0x05, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x19,
+	0x0a, 0x08, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0c, 0x69, 0x73, 0x5f, 0x62, 0x75, 0x79, 0x5f,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x42,
+	0x75, 0x79, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x32, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x6d, 0x69, 0x6e, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70,
+	0x72, 0x69, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x79, 0x70, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x6d, 0x61,
+	0x69, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x54, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x37, 0x0a, 0x06, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x12,
+	0x2d, 0x0a, 0x06, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x06, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x22, 0xf3,
+	0x02, 0x0a, 0x0b, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x79,
+	0x70, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x79, 0x70,
+	0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x14, 0x0a,
+	0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x20, 0x0a, 0x0c, 0x69, 0x73, 0x5f, 0x62, 0x75, 0x79, 0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x42, 0x75, 0x79, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x12, 0x32, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x06, 0x69,
+	0x73, 0x73, 0x75, 0x65, 0x64, 0x12, 0x3d, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x64, 0x22, 0x45, 0x0a, 0x0c, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0xc7, 0x04, 0x0a, 0x08,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x79, 0x6f,
+	0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x75, 0x79, 0x6f, 0x75, 0x74,
+	0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c,
+	0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49,
+	0x64, 0x12, 0x3d, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64,
+	0x12, 0x3b, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x65, 0x49, 0x73, 0x73, 0x75, 0x65, 0x64, 0x12, 0x28, 0x0a,
+	0x10, 0x64, 0x61, 0x79, 0x73, 0x5f, 0x74, 0x6f, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x64, 0x61, 0x79, 0x73, 0x54, 0x6f, 0x43,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x65, 0x6e, 0x64, 0x5f, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x65, 0x6e, 0x64, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x27, 0x0a, 0x0f, 0x66, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x72, 0x70, 0x6f, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x66, 0x6f, 0x72, 0x43, 0x6f, 0x72,
+	0x70, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x15, 0x69, 0x73, 0x73, 0x75,
+	0x65, 0x72, 0x5f, 0x63, 0x6f, 0x72, 0x70, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69,
+	0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x43,
+	0x6f, 0x72, 0x70, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09,
+	0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x22, 0xb4, 0x02, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x2a, 0x0a, 0x11, 0x69, 0x73, 0x5f, 0x62, 0x6c, 0x75,
+	0x65, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x5f, 0x63, 0x6f, 0x70, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0f, 0x69, 0x73, 0x42, 0x6c, 0x75, 0x65, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x43, 0x6f,
+	0x70, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x49, 0x6e, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x69, 0x74, 0x65, 0x6d, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x13,
+	0x6d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x6d, 0x61, 0x74, 0x65, 0x72,
+	0x69, 0x61, 0x6c, 0x45, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x45, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65,
+	0x6e, 0x63, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x79, 0x70, 0x65, 0x49, 0x64, 0x22, 0x73, 0x0a, 0x0b,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x42, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x61, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x62, 0x69, 0x64, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x61,
+	0x74, 0x65, 0x5f, 0x62, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x64, 0x61, 0x74, 0x65, 0x42, 0x69,
+	0x64, 0x22, 0xbf, 0x01, 0x0a, 0x0c, 0x43, 0x6f, 0x75, 0x72, 0x69, 0x65, 0x72, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x65, 0x6e,
+	0x64, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x65, 0x6e, 0x64, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6a,
+	0x75, 0x6d, 0x70, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x73, 0x65, 0x63,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x68, 0x69, 0x67, 0x68, 0x53, 0x65, 0x63, 0x12,
+	0x17, 0x0a, 0x07, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x6c, 0x6f, 0x77, 0x53, 0x65, 0x63, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x75, 0x6c, 0x6c,
+	0x5f, 0x73, 0x65, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6e, 0x75, 0x6c, 0x6c,
+	0x53, 0x65, 0x63, 0x22, 0xac, 0x01, 0x0a, 0x0b, 0x43, 0x6f, 0x75, 0x72, 0x69, 0x65, 0x72, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x32, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x72, 0x69, 0x65, 0x72, 0x52, 0x6f, 0x75, 0x74, 0x65,
+	0x52, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0c, 0x69, 0x73, 0x6b, 0x5f, 0x70,
+	0x65, 0x72, 0x5f, 0x6a, 0x75, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x69,
+	0x73, 0x6b, 0x50, 0x65, 0x72, 0x4a, 0x75, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x0a, 0x69, 0x73, 0x6b,
+	0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6d, 0x33, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x69,
+	0x73, 0x6b, 0x50, 0x65, 0x72, 0x4d, 0x33, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6c, 0x6c, 0x61,
+	0x74, 0x65, 0x72, 0x61, 0x6c, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0f, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x52, 0x61, 0x74,
+	0x69, 0x6f, 0x22, 0xaa, 0x02, 0x0a, 0x0c, 0x46, 0x75, 0x6c, 0x6c, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x12, 0x34, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x52,
+	0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x12, 0x32, 0x0a, 0x05, 0x69, 0x74, 0x65,
+	0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65,
+	0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x2f, 0x0a,
+	0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x61,
+	0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x42, 0x69, 0x64, 0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12, 0x27,
+	0x0a, 0x0f, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x35, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x72,
+	0x69, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d, 0x61, 0x72, 0x6b,
+	0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x72, 0x69,
+	0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x72, 0x69, 0x65, 0x72, 0x22,
+	0x47, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x12, 0x3a, 0x0a, 0x09,
+	0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x75, 0x6c, 0x6c, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x52, 0x09, 0x63,
+	0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x22, 0x91, 0x03, 0x0a, 0x0e, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12,
+	0x3d, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x12, 0x2f,
+	0x0a, 0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6d,
+	0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x42, 0x69, 0x64, 0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12,
+	0x32, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x3d, 0x0a,
+	0x0c, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0b, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x22, 0x4b, 0x0a, 0x0f,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12,
+	0x38, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x32, 0xec, 0x01, 0x0a, 0x0b, 0x4d, 0x61,
+	0x72, 0x6b, 0x65, 0x74, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x46, 0x0a, 0x09, 0x53, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x20, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77,
+	0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65,
+	0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30,
+	0x01, 0x12, 0x45, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x12, 0x20,
+	0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x12, 0x4e, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x43,
+	0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x12, 0x23, 0x2e, 0x6d, 0x61, 0x72, 0x6b, 0x65,
+	0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x73, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x6f, 0x72, 0x6e, 0x6f, 0x2f,
+	0x65, 0x76, 0x65, 0x2d, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2f,
+	0x6d, 0x61, 0x72, 0x6b, 0x65, 0x74, 0x77, 0x61, 0x74, 0x63, 0x68, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_marketwatch_v1_marketwatch_proto_rawDescOnce sync.Once
+	file_marketwatch_v1_marketwatch_proto_rawDescData = file_marketwatch_v1_marketwatch_proto_rawDesc
+)
+
+func file_marketwatch_v1_marketwatch_proto_rawDescGZIP() []byte {
+	file_marketwatch_v1_marketwatch_proto_rawDescOnce.Do(func() {
+		file_marketwatch_v1_marketwatch_proto_rawDescData = protoimpl.X.CompressGZIP(file_marketwatch_v1_marketwatch_proto_rawDescData)
+	})
+	return file_marketwatch_v1_marketwatch_proto_rawDescData
+}
+
+var file_marketwatch_v1_marketwatch_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_marketwatch_v1_marketwatch_proto_goTypes = []interface{}{
+	(*SubscribeRequest)(nil),      // 0: marketwatch.v1.SubscribeRequest
+	(*Event)(nil),                 // 1: marketwatch.v1.Event
+	(*GetOrdersRequest)(nil),      // 2: marketwatch.v1.GetOrdersRequest
+	(*GetContractsRequest)(nil),   // 3: marketwatch.v1.GetContractsRequest
+	(*Order)(nil),                 // 4: marketwatch.v1.Order
+	(*Orders)(nil),                // 5: marketwatch.v1.Orders
+	(*OrderChange)(nil),           // 6: marketwatch.v1.OrderChange
+	(*OrderChanges)(nil),          // 7: marketwatch.v1.OrderChanges
+	(*Contract)(nil),              // 8: marketwatch.v1.Contract
+	(*ContractItem)(nil),          // 9: marketwatch.v1.ContractItem
+	(*ContractBid)(nil),           // 10: marketwatch.v1.ContractBid
+	(*CourierRoute)(nil),          // 11: marketwatch.v1.CourierRoute
+	(*CourierInfo)(nil),           // 12: marketwatch.v1.CourierInfo
+	(*FullContract)(nil),          // 13: marketwatch.v1.FullContract
+	(*Contracts)(nil),             // 14: marketwatch.v1.Contracts
+	(*ContractChange)(nil),        // 15: marketwatch.v1.ContractChange
+	(*ContractChanges)(nil),       // 16: marketwatch.v1.ContractChanges
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_marketwatch_v1_marketwatch_proto_depIdxs = []int32{
+	5,  // 0: marketwatch.v1.Event.orders:type_name -> marketwatch.v1.Orders
+	7,  // 1: marketwatch.v1.Event.order_changes:type_name -> marketwatch.v1.OrderChanges
+	14, // 2: marketwatch.v1.Event.contracts:type_name -> marketwatch.v1.Contracts
+	16, // 3: marketwatch.v1.Event.contract_changes:type_name -> marketwatch.v1.ContractChanges
+	17, // 4: marketwatch.v1.Order.issued:type_name -> google.protobuf.Timestamp
+	4,  // 5: marketwatch.v1.Orders.orders:type_name -> marketwatch.v1.Order
+	17, // 6: marketwatch.v1.OrderChange.issued:type_name -> google.protobuf.Timestamp
+	17, // 7: marketwatch.v1.OrderChange.time_changed:type_name -> google.protobuf.Timestamp
+	6,  // 8: marketwatch.v1.OrderChanges.changes:type_name -> marketwatch.v1.OrderChange
+	17, // 9: marketwatch.v1.Contract.date_expired:type_name -> google.protobuf.Timestamp
+	17, // 10: marketwatch.v1.Contract.date_issued:type_name -> google.protobuf.Timestamp
+	17, // 11: marketwatch.v1.ContractBid.date_bid:type_name -> google.protobuf.Timestamp
+	11, // 12: marketwatch.v1.CourierInfo.route:type_name -> marketwatch.v1.CourierRoute
+	8,  // 13: marketwatch.v1.FullContract.contract:type_name -> marketwatch.v1.Contract
+	9,  // 14: marketwatch.v1.FullContract.items:type_name -> marketwatch.v1.ContractItem
+	10, // 15: marketwatch.v1.FullContract.bids:type_name -> marketwatch.v1.ContractBid
+	12, // 16: marketwatch.v1.FullContract.courier:type_name -> marketwatch.v1.CourierInfo
+	13, // 17: marketwatch.v1.Contracts.contracts:type_name -> marketwatch.v1.FullContract
+	17, // 18: marketwatch.v1.ContractChange.date_expired:type_name -> google.protobuf.Timestamp
+	10, // 19: marketwatch.v1.ContractChange.bids:type_name -> marketwatch.v1.ContractBid
+	9,  // 20: marketwatch.v1.ContractChange.items:type_name -> marketwatch.v1.ContractItem
+	17, // 21: marketwatch.v1.ContractChange.time_changed:type_name -> google.protobuf.Timestamp
+	15, // 22: marketwatch.v1.ContractChanges.changes:type_name -> marketwatch.v1.ContractChange
+	0,  // 23: marketwatch.v1.MarketWatch.Subscribe:input_type -> marketwatch.v1.SubscribeRequest
+	2,  // 24: marketwatch.v1.MarketWatch.GetOrders:input_type -> marketwatch.v1.GetOrdersRequest
+	3,  // 25: marketwatch.v1.MarketWatch.GetContracts:input_type -> marketwatch.v1.GetContractsRequest
+	1,  // 26: marketwatch.v1.MarketWatch.Subscribe:output_type -> marketwatch.v1.Event
+	5,  // 27: marketwatch.v1.MarketWatch.GetOrders:output_type -> marketwatch.v1.Orders
+	14, // 28: marketwatch.v1.MarketWatch.GetContracts:output_type -> marketwatch.v1.Contracts
+	26, // [26:29] is the sub-list for method output_type
+	23, // [23:26] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_marketwatch_v1_marketwatch_proto_init() }
+func file_marketwatch_v1_marketwatch_proto_init() {
+	if File_marketwatch_v1_marketwatch_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_marketwatch_v1_marketwatch_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOrdersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetContractsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Order); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Orders); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OrderChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OrderChanges); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Contract); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContractItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContractBid); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CourierRoute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CourierInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FullContract); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Contracts); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContractChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_marketwatch_v1_marketwatch_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContractChanges); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_marketwatch_v1_marketwatch_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*Event_Orders)(nil),
+		(*Event_OrderChanges)(nil),
+		(*Event_Contracts)(nil),
+		(*Event_ContractChanges)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_marketwatch_v1_marketwatch_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_marketwatch_v1_marketwatch_proto_goTypes,
+		DependencyIndexes: file_marketwatch_v1_marketwatch_proto_depIdxs,
+		MessageInfos:      file_marketwatch_v1_marketwatch_proto_msgTypes,
+	}.Build()
+	File_marketwatch_v1_marketwatch_proto = out.File
+	file_marketwatch_v1_marketwatch_proto_rawDesc = nil
+	file_marketwatch_v1_marketwatch_proto_goTypes = nil
+	file_marketwatch_v1_marketwatch_proto_depIdxs = nil
+}
@@ -0,0 +1,219 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: marketwatch/v1/marketwatch.proto
+
+package marketwatchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MarketWatch_Subscribe_FullMethodName    = "/marketwatch.v1.MarketWatch/Subscribe"
+	MarketWatch_GetOrders_FullMethodName    = "/marketwatch.v1.MarketWatch/GetOrders"
+	MarketWatch_GetContracts_FullMethodName = "/marketwatch.v1.MarketWatch/GetContracts"
+)
+
+// MarketWatchClient is the client API for MarketWatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MarketWatchClient interface {
+	// Subscribe to broadcasts. There is no initial dump, query the current
+	// state with GetOrders and GetContracts instead.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketWatch_SubscribeClient, error)
+	// GetOrders in the market stores
+	GetOrders(ctx context.Context, in *GetOrdersRequest, opts ...grpc.CallOption) (*Orders, error)
+	// GetContracts in the contract stores
+	GetContracts(ctx context.Context, in *GetContractsRequest, opts ...grpc.CallOption) (*Contracts, error)
+}
+
+type marketWatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketWatchClient(cc grpc.ClientConnInterface) MarketWatchClient {
+	return &marketWatchClient{cc}
+}
+
+func (c *marketWatchClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketWatch_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MarketWatch_ServiceDesc.Streams[0], MarketWatch_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketWatchSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MarketWatch_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type marketWatchSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketWatchSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *marketWatchClient) GetOrders(ctx context.Context, in *GetOrdersRequest, opts ...grpc.CallOption) (*Orders, error) {
+	out := new(Orders)
+	err := c.cc.Invoke(ctx, MarketWatch_GetOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketWatchClient) GetContracts(ctx context.Context, in *GetContractsRequest, opts ...grpc.CallOption) (*Contracts, error) {
+	out := new(Contracts)
+	err := c.cc.Invoke(ctx, MarketWatch_GetContracts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarketWatchServer is the server API for MarketWatch service.
+// All implementations must embed UnimplementedMarketWatchServer
+// for forward compatibility
+type MarketWatchServer interface {
+	// Subscribe to broadcasts. There is no initial dump, query the current
+	// state with GetOrders and GetContracts instead.
+	Subscribe(*SubscribeRequest, MarketWatch_SubscribeServer) error
+	// GetOrders in the market stores
+	GetOrders(context.Context, *GetOrdersRequest) (*Orders, error)
+	// GetContracts in the contract stores
+	GetContracts(context.Context, *GetContractsRequest) (*Contracts, error)
+	mustEmbedUnimplementedMarketWatchServer()
+}
+
+// UnimplementedMarketWatchServer must be embedded to have forward compatible implementations.
+type UnimplementedMarketWatchServer struct {
+}
+
+func (UnimplementedMarketWatchServer) Subscribe(*SubscribeRequest, MarketWatch_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedMarketWatchServer) GetOrders(context.Context, *GetOrdersRequest) (*Orders, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrders not implemented")
+}
+func (UnimplementedMarketWatchServer) GetContracts(context.Context, *GetContractsRequest) (*Contracts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContracts not implemented")
+}
+func (UnimplementedMarketWatchServer) mustEmbedUnimplementedMarketWatchServer() {}
+
+// UnsafeMarketWatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketWatchServer will
+// result in compilation errors.
+type UnsafeMarketWatchServer interface {
+	mustEmbedUnimplementedMarketWatchServer()
+}
+
+func RegisterMarketWatchServer(s grpc.ServiceRegistrar, srv MarketWatchServer) {
+	s.RegisterService(&MarketWatch_ServiceDesc, srv)
+}
+
+func _MarketWatch_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketWatchServer).Subscribe(m, &marketWatchSubscribeServer{stream})
+}
+
+type MarketWatch_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type marketWatchSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketWatchSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MarketWatch_GetOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketWatchServer).GetOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketWatch_GetOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketWatchServer).GetOrders(ctx, req.(*GetOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketWatch_GetContracts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContractsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketWatchServer).GetContracts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketWatch_GetContracts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketWatchServer).GetContracts(ctx, req.(*GetContractsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MarketWatch_ServiceDesc is the grpc.ServiceDesc for MarketWatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketWatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "marketwatch.v1.MarketWatch",
+	HandlerType: (*MarketWatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOrders",
+			Handler:    _MarketWatch_GetOrders_Handler,
+		},
+		{
+			MethodName: "GetContracts",
+			Handler:    _MarketWatch_GetContracts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _MarketWatch_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "marketwatch/v1/marketwatch.proto",
+}
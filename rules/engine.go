@@ -0,0 +1,275 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/contorno/eve-marketwatch/sde"
+	"github.com/getsentry/sentry-go"
+	"golang.org/x/exp/slog"
+)
+
+// How many alerts are kept for lookup
+const alertHistory = 10000
+
+// Engine holds the rules and the alerts they raised
+type Engine struct {
+	static *sde.Store
+	log    *slog.Logger
+
+	// rules file, empty to keep rules in memory only
+	path     string
+	modified time.Time
+
+	// one save at a time, so the file always holds a whole snapshot
+	saveMutex sync.Mutex
+
+	mutex sync.RWMutex
+	rules map[string]Rule
+
+	alertMutex sync.RWMutex
+	alerts     []Alert // ring buffer
+	nextAlert  int
+}
+
+// NewEngine creates a rules engine. Rules are loaded from and saved to path
+// when it is set, creating the file if it does not exist.
+func NewEngine(path string, static *sde.Store) (*Engine, error) {
+	e := &Engine{
+		static: static,
+		log:    logging.For("rules"),
+		path:   path,
+		rules:  make(map[string]Rule),
+	}
+	if path == "" {
+		return e, nil
+	}
+
+	err := e.load()
+	if errors.Is(err, os.ErrNotExist) {
+		return e, e.save()
+	}
+	return e, err
+}
+
+// Rules returns all the rules sorted by ID
+func (e *Engine) Rules() []Rule {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	rules := make([]Rule, 0, len(e.rules))
+	for _, r := range e.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(
+		rules, func(i, j int) bool {
+			return rules[i].ID < rules[j].ID
+		},
+	)
+	return rules
+}
+
+// Empty is true when there are no rules to check
+func (e *Engine) Empty() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return len(e.rules) == 0
+}
+
+// Put adds or replaces a rule
+func (e *Engine) Put(r Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	e.mutex.Lock()
+	e.rules[r.ID] = r
+	e.mutex.Unlock()
+	return e.save()
+}
+
+// Delete a rule, returning false if it did not exist
+func (e *Engine) Delete(id string) (bool, error) {
+	e.mutex.Lock()
+	_, ok := e.rules[id]
+	delete(e.rules, id)
+	e.mutex.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, e.save()
+}
+
+// Check a subject against every rule, storing and returning the alerts raised
+func (e *Engine) Check(s Subject, payload interface{}) []Alert {
+	static := e.static.Data()
+	now := time.Now().UTC()
+
+	var alerts []Alert
+	e.mutex.RLock()
+	for _, r := range e.rules {
+		if r.Match(s, static) {
+			alerts = append(
+				alerts, Alert{
+					RuleID:   r.ID,
+					RuleName: r.Name,
+					Action:   s.Action,
+					RegionID: s.RegionID,
+					Time:     now,
+					Payload:  payload,
+				},
+			)
+		}
+	}
+	e.mutex.RUnlock()
+
+	if len(alerts) > 0 {
+		e.store(alerts)
+	}
+	return alerts
+}
+
+// store alerts in the ring buffer
+func (e *Engine) store(alerts []Alert) {
+	e.alertMutex.Lock()
+	defer e.alertMutex.Unlock()
+	for _, a := range alerts {
+		if len(e.alerts) < alertHistory {
+			e.alerts = append(e.alerts, a)
+			continue
+		}
+		e.alerts[e.nextAlert] = a
+		e.nextAlert = (e.nextAlert + 1) % alertHistory
+	}
+}
+
+// Alerts returns stored alerts, oldest first, raised after since and
+// optionally for a single rule. At most limit alerts are returned, the newest.
+func (e *Engine) Alerts(ruleID string, since time.Time, limit int) []Alert {
+	e.alertMutex.RLock()
+	defer e.alertMutex.RUnlock()
+
+	found := []Alert{}
+	n := len(e.alerts)
+	for i := 0; i < n; i++ {
+		a := e.alerts[(e.nextAlert+i)%n]
+		if ruleID != "" && a.RuleID != ruleID {
+			continue
+		}
+		if !a.Time.After(since) {
+			continue
+		}
+		found = append(found, a)
+	}
+	if limit > 0 && len(found) > limit {
+		found = found[len(found)-limit:]
+	}
+	return found
+}
+
+// Watch the rules file every interval, reloading it when it changes
+func (e *Engine) Watch(interval time.Duration, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#rules-watch")
+		},
+	)
+	if e.path == "" {
+		return
+	}
+	logger := logging.WithHub(e.log, localHub)
+
+	for {
+		time.Sleep(interval)
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			logger.Error("checking the rules", logging.Error, err)
+			continue
+		}
+
+		e.mutex.RLock()
+		changed := info.ModTime().After(e.modified)
+		e.mutex.RUnlock()
+		if !changed {
+			continue
+		}
+
+		err = e.load()
+		if err != nil {
+			// Keep the old rules until the file is fixed
+			logger.Error("reloading the rules", logging.Error, err)
+			continue
+		}
+		logger.Info("reloaded rules", "path", e.path)
+	}
+}
+
+// load the rules file, replacing the rules
+func (e *Engine) load() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(e.path)
+	if err != nil {
+		return err
+	}
+
+	var list []Rule
+	err = json.Unmarshal(raw, &list)
+	if err != nil {
+		return err
+	}
+	rules := make(map[string]Rule)
+	for _, r := range list {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		rules[r.ID] = r
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.rules = rules
+	e.modified = info.ModTime()
+	return nil
+}
+
+// save the rules file
+func (e *Engine) save() error {
+	if e.path == "" {
+		return nil
+	}
+	e.saveMutex.Lock()
+	defer e.saveMutex.Unlock()
+
+	raw, err := json.MarshalIndent(e.Rules(), "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so a watcher never reads half a file
+	tmp := e.path + ".tmp"
+	err = os.WriteFile(tmp, raw, 0o600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, e.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.modified = info.ModTime()
+	return nil
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+)
+
+// ServeRules manages rules over http.
+//
+//	GET    list the rules
+//	POST   add or replace the rule in the body
+//	DELETE remove the rule in the id parameter
+func (e *Engine) ServeRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		e.writeJSON(w, e.Rules())
+	case http.MethodPost, http.MethodPut:
+		var rule Rule
+		err := json.NewDecoder(r.Body).Decode(&rule)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = rule.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = e.Put(rule); err != nil {
+			e.log.Error("saving a rule", "rule_id", rule.ID, logging.Error, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		e.writeJSON(w, rule)
+	case http.MethodDelete:
+		found, err := e.Delete(r.URL.Query().Get("id"))
+		if err != nil {
+			e.log.Error("deleting a rule", logging.Error, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AlertQuery selects stored alerts
+type AlertQuery struct {
+	RuleID string
+	Since  time.Time
+	Limit  int
+}
+
+// ParseAlertQuery reads the rule_id, since (RFC 3339) and limit parameters
+func ParseAlertQuery(r *http.Request) (AlertQuery, error) {
+	q := r.URL.Query()
+	query := AlertQuery{RuleID: q.Get("rule_id"), Limit: 1000}
+
+	if v := q.Get("since"); v != "" {
+		var err error
+		query.Since, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, err
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(v)
+		if err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// ServeAlerts lists stored alerts, filtered by the rule_id, since (RFC 3339)
+// and limit parameters.
+func (e *Engine) ServeAlerts(w http.ResponseWriter, r *http.Request) {
+	q, err := ParseAlertQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.writeJSON(w, e.Alerts(q.RuleID, q.Since, q.Limit))
+}
+
+func (e *Engine) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		e.log.Error("writing the response", logging.Error, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package rules checks market events against declarative rules and raises alerts.
+package rules
+
+import (
+	"errors"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/sde"
+)
+
+// Kinds of subjects rules apply to
+const (
+	KindOrder    = "order"
+	KindContract = "contract"
+)
+
+// Rule describes the events that raise an alert. Empty fields match anything,
+// lists match any of their entries.
+type Rule struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Kind          string   `json:"kind"`
+	Actions       []string `json:"actions,omitempty"`
+	TypeIDs       []int32  `json:"type_ids,omitempty"`
+	GroupIDs      []int32  `json:"group_ids,omitempty"`
+	CategoryIDs   []int32  `json:"category_ids,omitempty"`
+	RegionIDs     []int64  `json:"region_ids,omitempty"`
+	LocationIDs   []int64  `json:"location_ids,omitempty"`
+	IsBuyOrder    *bool    `json:"is_buy_order,omitempty"`
+	ContractTypes []string `json:"contract_types,omitempty"`
+	MinPrice      float64  `json:"min_price,omitempty"`
+	MaxPrice      float64  `json:"max_price,omitempty"`
+}
+
+// Subject is an order or contract event to check the rules against
+type Subject struct {
+	Kind         string
+	Action       string
+	RegionID     int64
+	LocationID   int64
+	TypeIDs      []int32 // the order type, or the items of a contract
+	IsBuyOrder   bool
+	ContractType string
+	Price        float64
+}
+
+// Alert is raised when a subject matches a rule
+type Alert struct {
+	RuleID   string      `json:"rule_id"`
+	RuleName string      `json:"rule_name,omitempty"`
+	Action   string      `json:"action"`
+	RegionID int64       `json:"region_id"`
+	Time     time.Time   `json:"time"`
+	Payload  interface{} `json:"payload"`
+}
+
+// Validate checks a rule can be used
+func (r Rule) Validate() error {
+	if r.ID == "" {
+		return errors.New("rule id is required")
+	}
+	if r.Kind != KindOrder && r.Kind != KindContract {
+		return errors.New("rule kind must be order or contract")
+	}
+	if r.MaxPrice != 0 && r.MaxPrice < r.MinPrice {
+		return errors.New("rule max_price is below min_price")
+	}
+	return nil
+}
+
+// Match checks a subject against the rule. Static data is needed for group
+// and category rules, which never match without it.
+func (r Rule) Match(s Subject, static *sde.Data) bool {
+	if s.Kind != r.Kind {
+		return false
+	}
+	if len(r.Actions) > 0 && !contains(r.Actions, s.Action) {
+		return false
+	}
+	if len(r.RegionIDs) > 0 && !contains(r.RegionIDs, s.RegionID) {
+		return false
+	}
+	if len(r.LocationIDs) > 0 && !contains(r.LocationIDs, s.LocationID) {
+		return false
+	}
+	if r.IsBuyOrder != nil && (s.Kind != KindOrder || s.IsBuyOrder != *r.IsBuyOrder) {
+		return false
+	}
+	if len(r.ContractTypes) > 0 && !contains(r.ContractTypes, s.ContractType) {
+		return false
+	}
+	if r.MinPrice != 0 && s.Price < r.MinPrice {
+		return false
+	}
+	if r.MaxPrice != 0 && s.Price > r.MaxPrice {
+		return false
+	}
+	return r.matchTypes(s.TypeIDs, static)
+}
+
+// matchTypes checks any of the types against the type, group and category lists
+func (r Rule) matchTypes(typeIDs []int32, static *sde.Data) bool {
+	if len(r.TypeIDs) == 0 && len(r.GroupIDs) == 0 && len(r.CategoryIDs) == 0 {
+		return true
+	}
+	for _, typeID := range typeIDs {
+		if contains(r.TypeIDs, typeID) {
+			return true
+		}
+		if static == nil || (len(r.GroupIDs) == 0 && len(r.CategoryIDs) == 0) {
+			continue
+		}
+		t, ok := static.Types[typeID]
+		if !ok {
+			continue
+		}
+		if contains(r.GroupIDs, t.GroupID) {
+			return true
+		}
+		if contains(r.CategoryIDs, static.Groups[t.GroupID].CategoryID) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
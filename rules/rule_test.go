@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleMatch(t *testing.T) {
+	sell := false
+	cheapPlex := Rule{
+		ID:         "cheap-plex",
+		Kind:       KindOrder,
+		TypeIDs:    []int32{44992},
+		RegionIDs:  []int64{10000002},
+		IsBuyOrder: &sell,
+		MaxPrice:   2500000,
+	}
+	assert.Nil(t, cheapPlex.Validate())
+
+	order := Subject{
+		Kind:     KindOrder,
+		Action:   "addition",
+		RegionID: 10000002,
+		TypeIDs:  []int32{44992},
+		Price:    2400000,
+	}
+	assert.True(t, cheapPlex.Match(order, nil))
+
+	order.Price = 2600000
+	assert.False(t, cheapPlex.Match(order, nil))
+
+	order.Price = 2400000
+	order.IsBuyOrder = true
+	assert.False(t, cheapPlex.Match(order, nil))
+
+	contract := Subject{
+		Kind:     KindContract,
+		Action:   "contractAddition",
+		RegionID: 10000002,
+		TypeIDs:  []int32{34, 44992},
+	}
+	assert.False(t, cheapPlex.Match(contract, nil))
+	assert.True(t, Rule{ID: "plex", Kind: KindContract, TypeIDs: []int32{44992}}.Match(contract, nil))
+
+	assert.NotNil(t, Rule{ID: "x", Kind: "history"}.Validate())
+}
+
+func TestEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	e, err := NewEngine(path, nil)
+	assert.Nil(t, err)
+	assert.True(t, e.Empty())
+
+	assert.Nil(t, e.Put(Rule{ID: "plex", Kind: KindOrder, TypeIDs: []int32{44992}}))
+
+	// Rules survive a restart
+	e, err = NewEngine(path, nil)
+	assert.Nil(t, err)
+	assert.Len(t, e.Rules(), 1)
+
+	start := time.Now().Add(-time.Second)
+	alerts := e.Check(Subject{Kind: KindOrder, Action: "change", TypeIDs: []int32{44992}}, "payload")
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "plex", alerts[0].RuleID)
+	assert.Len(t, e.Alerts("plex", start, 0), 1)
+	assert.Len(t, e.Alerts("other", start, 0), 0)
+
+	found, err := e.Delete("plex")
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.True(t, e.Empty())
+}
+
+func TestEngineConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	e, err := NewEngine(path, nil)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, e.Put(Rule{ID: strconv.Itoa(i), Kind: KindOrder}))
+		}(i)
+	}
+	wg.Wait()
+
+	// The file holds every rule
+	e, err = NewEngine(path, nil)
+	assert.Nil(t, err)
+	assert.Len(t, e.Rules(), 20)
+}
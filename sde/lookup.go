@@ -0,0 +1,85 @@
+package sde
+
+// TypeInfo is a type with its group, category and market group path
+type TypeInfo struct {
+	Type
+	GroupName    string   `json:"group_name,omitempty"`
+	CategoryID   int32    `json:"category_id,omitempty"`
+	CategoryName string   `json:"category_name,omitempty"`
+	MarketGroups []string `json:"market_groups,omitempty"` // root first
+}
+
+// Location is a station or solar system placed in the universe hierarchy
+type Location struct {
+	StationID         int64   `json:"station_id,omitempty"`
+	StationName       string  `json:"station_name,omitempty"`
+	SolarSystemID     int32   `json:"solar_system_id"`
+	SolarSystemName   string  `json:"solar_system_name"`
+	Security          float64 `json:"security"`
+	ConstellationID   int32   `json:"constellation_id"`
+	ConstellationName string  `json:"constellation_name"`
+	RegionID          int32   `json:"region_id"`
+	RegionName        string  `json:"region_name"`
+}
+
+// TypeInfo looks up a type and where it belongs
+func (d *Data) TypeInfo(typeID int32) (TypeInfo, bool) {
+	t, ok := d.Types[typeID]
+	if !ok {
+		return TypeInfo{}, false
+	}
+
+	info := TypeInfo{Type: t}
+	if g, ok := d.Groups[t.GroupID]; ok {
+		info.GroupName = g.Name
+		info.CategoryID = g.CategoryID
+		info.CategoryName = d.Categories[g.CategoryID].Name
+	}
+
+	// Walk up the market tree, guarding against loops
+	for id, depth := t.MarketGroupID, 0; id != 0 && depth < 16; depth++ {
+		m, ok := d.MarketGroups[id]
+		if !ok {
+			break
+		}
+		info.MarketGroups = append([]string{m.Name}, info.MarketGroups...)
+		id = m.ParentID
+	}
+
+	return info, true
+}
+
+// Location looks up a station or solar system
+func (d *Data) Location(locationID int64) (Location, bool) {
+	var loc Location
+	if station, ok := d.Stations[locationID]; ok {
+		loc.StationID = station.ID
+		loc.StationName = station.Name
+	}
+
+	systemID, ok := d.SystemOf(locationID)
+	if !ok {
+		return loc, false
+	}
+	return d.placeSystem(loc, systemID), true
+}
+
+// SystemLocation places a solar system in the hierarchy
+func (d *Data) SystemLocation(systemID int32) (Location, bool) {
+	if _, ok := d.Systems[systemID]; !ok {
+		return Location{}, false
+	}
+	return d.placeSystem(Location{}, systemID), true
+}
+
+func (d *Data) placeSystem(loc Location, systemID int32) Location {
+	system := d.Systems[systemID]
+	loc.SolarSystemID = system.ID
+	loc.SolarSystemName = system.Name
+	loc.Security = system.Security
+	loc.ConstellationID = system.ConstellationID
+	loc.ConstellationName = d.Constellations[system.ConstellationID].Name
+	loc.RegionID = system.RegionID
+	loc.RegionName = d.Regions[system.RegionID].Name
+	return loc
+}
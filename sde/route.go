@@ -0,0 +1,77 @@
+package sde
+
+import "math"
+
+// Security classes of solar systems
+const (
+	HighSec = "high"
+	LowSec  = "low"
+	NullSec = "null"
+)
+
+// SecurityClass of a security status, rounded the way the game displays it
+func SecurityClass(security float64) string {
+	rounded := math.Round(security*10) / 10
+	switch {
+	case rounded >= 0.5:
+		return HighSec
+	case rounded > 0:
+		return LowSec
+	default:
+		return NullSec
+	}
+}
+
+// SystemOf finds the solar system of a station or solar system ID
+func (d *Data) SystemOf(locationID int64) (int32, bool) {
+	if station, ok := d.Stations[locationID]; ok {
+		return station.SystemID, true
+	}
+	if _, ok := d.Systems[int32(locationID)]; ok && locationID <= math.MaxInt32 {
+		return int32(locationID), true
+	}
+	return 0, false
+}
+
+// Route finds the shortest stargate route between two systems, including both ends.
+// Returns nil if there is no route.
+func (d *Data) Route(from, to int32) []int32 {
+	if _, ok := d.Systems[from]; !ok {
+		return nil
+	}
+	if from == to {
+		return []int32{from}
+	}
+
+	// Breadth first search, every jump costs the same
+	previous := map[int32]int32{from: from}
+	queue := []int32{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range d.jumps[current] {
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			previous[next] = current
+			if next == to {
+				return walkBack(previous, from, to)
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+func walkBack(previous map[int32]int32, from, to int32) []int32 {
+	route := []int32{to}
+	for current := to; current != from; {
+		current = previous[current]
+		route = append(route, current)
+	}
+	// Reverse into travel order
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route
+}
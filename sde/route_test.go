@@ -0,0 +1,41 @@
+package sde
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute(t *testing.T) {
+	d := &Data{
+		Systems: map[int32]System{
+			1: {ID: 1, Security: 0.9},
+			2: {ID: 2, Security: 0.45},
+			3: {ID: 3, Security: 0.2},
+			4: {ID: 4, Security: -0.3},
+			5: {ID: 5, Security: 1.0},
+		},
+		Stations: map[int64]Station{60000001: {ID: 60000001, SystemID: 4}},
+		jumps: map[int32][]int32{
+			1: {2, 5},
+			2: {1, 3},
+			3: {2, 4},
+			4: {3},
+			5: {1},
+		},
+	}
+
+	assert.Equal(t, []int32{5, 1, 2, 3, 4}, d.Route(5, 4))
+	assert.Equal(t, []int32{3}, d.Route(3, 3))
+	assert.Nil(t, d.Route(1, 6))
+
+	system, ok := d.SystemOf(60000001)
+	assert.True(t, ok)
+	assert.Equal(t, int32(4), system)
+	_, ok = d.SystemOf(1000000000001)
+	assert.False(t, ok)
+
+	assert.Equal(t, HighSec, SecurityClass(d.Systems[2].Security))
+	assert.Equal(t, LowSec, SecurityClass(d.Systems[3].Security))
+	assert.Equal(t, NullSec, SecurityClass(d.Systems[4].Security))
+}
@@ -0,0 +1,278 @@
+// Package sde loads static data from the csv exports of the EVE static data export.
+package sde
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Data holds the static universe data
+type Data struct {
+	Types          map[int32]Type
+	Groups         map[int32]Group
+	Categories     map[int32]Category
+	MarketGroups   map[int32]MarketGroup
+	Regions        map[int32]Region
+	Constellations map[int32]Constellation
+	Systems        map[int32]System
+	Stations       map[int64]Station
+	jumps          map[int32][]int32 // solarSystemID -> neighbours
+}
+
+// Type is an item type
+type Type struct {
+	ID            int32   `json:"type_id"`
+	Name          string  `json:"name"`
+	GroupID       int32   `json:"group_id"`
+	MarketGroupID int32   `json:"market_group_id,omitempty"`
+	Volume        float64 `json:"volume"`
+	Published     bool    `json:"published"`
+}
+
+// Group of item types
+type Group struct {
+	ID         int32  `json:"group_id"`
+	Name       string `json:"name"`
+	CategoryID int32  `json:"category_id"`
+}
+
+// Category of item groups
+type Category struct {
+	ID   int32  `json:"category_id"`
+	Name string `json:"name"`
+}
+
+// MarketGroup is a node of the market browser tree
+type MarketGroup struct {
+	ID       int32  `json:"market_group_id"`
+	Name     string `json:"name"`
+	ParentID int32  `json:"parent_group_id,omitempty"`
+}
+
+// Region of space
+type Region struct {
+	ID   int32  `json:"region_id"`
+	Name string `json:"name"`
+}
+
+// Constellation of solar systems
+type Constellation struct {
+	ID       int32  `json:"constellation_id"`
+	Name     string `json:"name"`
+	RegionID int32  `json:"region_id"`
+}
+
+// System is a solar system
+type System struct {
+	ID              int32   `json:"solar_system_id"`
+	ConstellationID int32   `json:"constellation_id"`
+	RegionID        int32   `json:"region_id"`
+	Name            string  `json:"name"`
+	Security        float64 `json:"security"`
+}
+
+// Station is an NPC station
+type Station struct {
+	ID       int64  `json:"station_id"`
+	Name     string `json:"name"`
+	SystemID int32  `json:"solar_system_id"`
+}
+
+// Files read from the static data directory
+var Files = []string{
+	"invTypes.csv",
+	"invGroups.csv",
+	"invCategories.csv",
+	"invMarketGroups.csv",
+	"mapRegions.csv",
+	"mapConstellations.csv",
+	"mapSolarSystems.csv",
+	"mapSolarSystemJumps.csv",
+	"staStations.csv",
+}
+
+// Load the static data from a directory of csv exports
+func Load(dir string) (*Data, error) {
+	d := &Data{
+		Types:          make(map[int32]Type),
+		Groups:         make(map[int32]Group),
+		Categories:     make(map[int32]Category),
+		MarketGroups:   make(map[int32]MarketGroup),
+		Regions:        make(map[int32]Region),
+		Constellations: make(map[int32]Constellation),
+		Systems:        make(map[int32]System),
+		Stations:       make(map[int64]Station),
+		jumps:          make(map[int32][]int32),
+	}
+
+	loaders := map[string]func(record) error{
+		"invTypes.csv": func(row record) error {
+			t := Type{
+				ID:            row.int32("typeID"),
+				Name:          row.get("typeName"),
+				GroupID:       row.int32("groupID"),
+				MarketGroupID: row.int32("marketGroupID"),
+				Volume:        row.float("volume"),
+				Published:     row.get("published") == "1",
+			}
+			d.Types[t.ID] = t
+			return row.err
+		},
+		"invGroups.csv": func(row record) error {
+			g := Group{
+				ID:         row.int32("groupID"),
+				Name:       row.get("groupName"),
+				CategoryID: row.int32("categoryID"),
+			}
+			d.Groups[g.ID] = g
+			return row.err
+		},
+		"invCategories.csv": func(row record) error {
+			c := Category{
+				ID:   row.int32("categoryID"),
+				Name: row.get("categoryName"),
+			}
+			d.Categories[c.ID] = c
+			return row.err
+		},
+		"invMarketGroups.csv": func(row record) error {
+			m := MarketGroup{
+				ID:       row.int32("marketGroupID"),
+				Name:     row.get("marketGroupName"),
+				ParentID: row.int32("parentGroupID"),
+			}
+			d.MarketGroups[m.ID] = m
+			return row.err
+		},
+		"mapRegions.csv": func(row record) error {
+			r := Region{
+				ID:   row.int32("regionID"),
+				Name: row.get("regionName"),
+			}
+			d.Regions[r.ID] = r
+			return row.err
+		},
+		"mapConstellations.csv": func(row record) error {
+			c := Constellation{
+				ID:       row.int32("constellationID"),
+				Name:     row.get("constellationName"),
+				RegionID: row.int32("regionID"),
+			}
+			d.Constellations[c.ID] = c
+			return row.err
+		},
+		"mapSolarSystems.csv": func(row record) error {
+			system := System{
+				ID:              row.int32("solarSystemID"),
+				ConstellationID: row.int32("constellationID"),
+				RegionID:        row.int32("regionID"),
+				Name:            row.get("solarSystemName"),
+				Security:        row.float("security"),
+			}
+			d.Systems[system.ID] = system
+			return row.err
+		},
+		"mapSolarSystemJumps.csv": func(row record) error {
+			from := row.int32("fromSolarSystemID")
+			d.jumps[from] = append(d.jumps[from], row.int32("toSolarSystemID"))
+			return row.err
+		},
+		"staStations.csv": func(row record) error {
+			station := Station{
+				ID:       row.int64("stationID"),
+				Name:     row.get("stationName"),
+				SystemID: row.int32("solarSystemID"),
+			}
+			d.Stations[station.ID] = station
+			return row.err
+		},
+	}
+
+	for _, file := range Files {
+		err := readCSV(filepath.Join(dir, file), loaders[file])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// record is a csv row addressed by header name
+type record struct {
+	header map[string]int
+	row    []string
+	err    error
+}
+
+func (r *record) get(name string) string {
+	i, ok := r.header[name]
+	if !ok || i >= len(r.row) {
+		return ""
+	}
+	return r.row[i]
+}
+
+func (r *record) int32(name string) int32 {
+	return int32(r.int64(name))
+}
+
+func (r *record) int64(name string) int64 {
+	v := r.get(name)
+	if v == "" || v == "None" {
+		return 0
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return i
+}
+
+func (r *record) float(name string) float64 {
+	v := r.get(name)
+	if v == "" || v == "None" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return f
+}
+
+// readCSV calls f for every row of a csv file with a header line
+func readCSV(path string, f func(record) error) error {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	reader := csv.NewReader(file)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		columns[h] = i
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(record{header: columns, row: row}); err != nil {
+			return err
+		}
+	}
+}
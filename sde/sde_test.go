@@ -0,0 +1,123 @@
+package sde
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixture copies the test exports to a temporary directory
+func fixture(t *testing.T) string {
+	dir := t.TempDir()
+	for _, file := range Files {
+		raw, err := os.ReadFile(filepath.Join("testdata", file))
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, file), raw, 0o600))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	d, err := Load("testdata")
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t, Type{ID: 34, Name: "Tritanium", GroupID: 18, MarketGroupID: 1857, Volume: 0.01, Published: true},
+		d.Types[34],
+	)
+	// None reads as zero
+	assert.Equal(t, int32(0), d.Types[587].MarketGroupID)
+	assert.Equal(t, Group{ID: 25, Name: "Frigate", CategoryID: 6}, d.Groups[25])
+	assert.Equal(t, Category{ID: 63, Name: "Special Edition Assets"}, d.Categories[63])
+	assert.Equal(t, MarketGroup{ID: 1857, Name: "Minerals", ParentID: 533}, d.MarketGroups[1857])
+	assert.Equal(t, Region{ID: 10000002, Name: "The Forge"}, d.Regions[10000002])
+	assert.Equal(t, Constellation{ID: 20000020, Name: "Kimotoro", RegionID: 10000002}, d.Constellations[20000020])
+	assert.Equal(
+		t, System{ID: 30000142, ConstellationID: 20000020, RegionID: 10000002, Name: "Jita", Security: 0.9459},
+		d.Systems[30000142],
+	)
+	assert.Equal(
+		t, Station{ID: 60003760, Name: "Jita IV - Moon 4 - Caldari Navy Assembly Plant", SystemID: 30000142},
+		d.Stations[60003760],
+	)
+	assert.Equal(t, []int32{30000142, 30000144}, d.Route(30000142, 30000144))
+
+	info, ok := d.TypeInfo(34)
+	assert.True(t, ok)
+	assert.Equal(t, "Material", info.CategoryName)
+	assert.Equal(t, []string{"Materials", "Minerals"}, info.MarketGroups)
+
+	loc, ok := d.Location(60003760)
+	assert.True(t, ok)
+	assert.Equal(t, "Jita", loc.SolarSystemName)
+	assert.Equal(t, "Kimotoro", loc.ConstellationName)
+	assert.Equal(t, "The Forge", loc.RegionName)
+}
+
+func TestLoadMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		body string
+	}{
+		{"not a number", "invTypes.csv", "typeID,typeName\nabc,Tritanium\n"},
+		{"bad float", "mapSolarSystems.csv", "solarSystemID,security\n30000142,high\n"},
+		{"ragged row", "invGroups.csv", "groupID,groupName\n18,Mineral,extra\n"},
+		{"empty file", "staStations.csv", ""},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := fixture(t)
+				assert.Nil(t, os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.body), 0o600))
+				_, err := Load(dir)
+				assert.NotNil(t, err)
+			},
+		)
+	}
+
+	dir := fixture(t)
+	assert.Nil(t, os.Remove(filepath.Join(dir, "invCategories.csv")))
+	_, err := Load(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStoreReload(t *testing.T) {
+	dir := fixture(t)
+	s, err := NewStore(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "Tritanium", s.Data().Types[34].Name)
+
+	reloaded := make(chan *Data, 1)
+	s.OnReload(
+		func(d *Data) {
+			reloaded <- d
+		},
+	)
+	go s.Watch(10*time.Millisecond, sentry.CurrentHub().Clone())
+
+	// A broken file is not loaded and the old data stays
+	later := time.Now().Add(time.Minute)
+	types := filepath.Join(dir, "invTypes.csv")
+	assert.Nil(t, os.WriteFile(types, []byte("typeID,typeName\nabc,Tritanium\n"), 0o600))
+	assert.Nil(t, os.Chtimes(types, later, later))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, reloaded, 0)
+	assert.Equal(t, "Tritanium", s.Data().Types[34].Name)
+
+	// Fixing it reloads
+	later = later.Add(time.Minute)
+	assert.Nil(t, os.WriteFile(types, []byte("typeID,typeName\n34,Tritanium II\n"), 0o600))
+	assert.Nil(t, os.Chtimes(types, later, later))
+	select {
+	case d := <-reloaded:
+		assert.Equal(t, "Tritanium II", d.Types[34].Name)
+		assert.Equal(t, d, s.Data())
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reloaded")
+	}
+}
@@ -0,0 +1,110 @@
+package sde
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/getsentry/sentry-go"
+	"golang.org/x/exp/slog"
+)
+
+// Store keeps the static data loaded and reloads it when the files change
+type Store struct {
+	dir      string
+	data     atomic.Pointer[Data]
+	modified time.Time
+	log      *slog.Logger
+
+	mutex    sync.Mutex
+	onReload []func(*Data)
+}
+
+// NewStore loads the static data from a directory
+func NewStore(dir string) (*Store, error) {
+	s := &Store{dir: dir, log: logging.For("sde")}
+	modified, err := s.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	d, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.data.Store(d)
+	s.modified = modified
+	return s, nil
+}
+
+// Data returns the current static data. Safe to call on a nil store.
+func (s *Store) Data() *Data {
+	if s == nil {
+		return nil
+	}
+	return s.data.Load()
+}
+
+// OnReload calls a handler with the new data after a reload.
+func (s *Store) OnReload(f func(*Data)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onReload = append(s.onReload, f)
+}
+
+// Watch the files for changes every interval, reloading when any file is newer
+func (s *Store) Watch(interval time.Duration, localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#sde-watch")
+		},
+	)
+	logger := logging.WithHub(s.log, localHub)
+
+	for {
+		time.Sleep(interval)
+
+		modified, err := s.lastModified()
+		if err != nil {
+			logger.Error("checking the static data", logging.Error, err)
+			continue
+		}
+		if !modified.After(s.modified) {
+			continue
+		}
+
+		d, err := Load(s.dir)
+		if err != nil {
+			// Keep the old data, files may still be copying
+			logger.Error("reloading the static data", logging.Error, err)
+			continue
+		}
+		s.data.Store(d)
+		s.modified = modified
+		logger.Info("reloaded static data", "dir", s.dir)
+
+		s.mutex.Lock()
+		handlers := s.onReload
+		s.mutex.Unlock()
+		for _, f := range handlers {
+			f(d)
+		}
+	}
+}
+
+// lastModified is the newest modification time of the files
+func (s *Store) lastModified() (time.Time, error) {
+	var newest time.Time
+	for _, file := range Files {
+		info, err := os.Stat(filepath.Join(s.dir, file))
+		if err != nil {
+			return newest, err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, nil
+}
@@ -0,0 +1,58 @@
+package stream
+
+// Store of orders and contracts that Apply keeps in step with the stream
+type Store interface {
+	PutOrder(regionID int64, o Order)
+	GetOrder(regionID, orderID int64) (Order, bool)
+	DeleteOrder(regionID, orderID int64)
+	PutContract(regionID int64, c FullContract)
+	GetContract(regionID int64, contractID int32) (FullContract, bool)
+	DeleteContract(regionID int64, contractID int32)
+}
+
+// Apply a broadcast of the market or contract channel to a store, reporting
+// if it held anything to apply. Deals are contract additions already, and
+// are left out.
+func Apply(s Store, m Message) bool {
+	switch p := m.Payload.(type) {
+	case []Order:
+		for _, o := range p {
+			s.PutOrder(m.RegionID, o)
+		}
+	case []OrderChange:
+		for _, c := range p {
+			if m.Action == "deletion" {
+				s.DeleteOrder(m.RegionID, c.OrderID)
+				continue
+			}
+			if o, ok := s.GetOrder(m.RegionID, c.OrderID); ok {
+				o.VolumeRemain = c.VolumeRemain
+				o.Price = c.Price
+				o.Duration = c.Duration
+				s.PutOrder(m.RegionID, o)
+			}
+		}
+	case []FullContract:
+		if m.Action != "contractAddition" {
+			return false
+		}
+		for _, c := range p {
+			s.PutContract(m.RegionID, c)
+		}
+	case []ContractChange:
+		for _, c := range p {
+			if m.Action == "contractDeletion" {
+				s.DeleteContract(m.RegionID, c.ContractId)
+				continue
+			}
+			if contract, ok := s.GetContract(m.RegionID, c.ContractId); ok {
+				contract.Bids = c.Bids
+				contract.Contract.Price = c.Price
+				s.PutContract(m.RegionID, contract)
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
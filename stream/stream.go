@@ -0,0 +1,119 @@
+// Package stream holds the messages of the market stream, shared by the
+// server and the client.
+package stream
+
+import (
+	"time"
+
+	"github.com/contorno/goesi/esi"
+)
+
+// Message wraps different payloads for the websocket interface
+type Message struct {
+	Action  string      `json:"action"`
+	Payload interface{} `json:"payload"`
+
+	// Region and cycle of market and contract payloads
+	RegionID int64  `json:"region_id,omitempty"`
+	Cycle    uint64 `json:"cycle,omitempty"`
+}
+
+// Order is a market order as ESI lists it
+type Order = esi.GetMarketsRegionIdOrders200Ok
+
+// OrderChange Details of what changed on an order
+type OrderChange struct {
+	OrderID      int64     `json:"order_id"`
+	LocationId   int64     `json:"location_id"`
+	TypeID       int32     `json:"type_id"`
+	VolumeChange int32     `json:"volume_change,omitempty"`
+	VolumeRemain int32     `json:"volume_remain,omitempty"`
+	Price        float64   `json:"price"`
+	Duration     int32     `json:"duration,omitempty"`
+	IsBuyOrder   bool      `json:"is_buy_order,omitempty"`
+	Issued       time.Time `json:"issued,omitempty"`
+	Changed      bool      `json:"-"`
+	TimeChanged  time.Time `json:"time_changed"`
+}
+
+// FullContract adds all three esi returns together
+type FullContract struct {
+	Contract       esi.GetContractsPublicRegionId200Ok          `json:"contract"`
+	Items          []esi.GetContractsPublicItemsContractId200Ok `json:"items,omitempty"`
+	Bids           []esi.GetContractsPublicBidsContractId200Ok  `json:"bids,omitempty"`
+	EstimatedValue float64                                      `json:"estimated_value,omitempty"`
+	PriceRatio     float64                                      `json:"price_ratio,omitempty"`
+	Courier        *CourierInfo                                 `json:"courier,omitempty"`
+}
+
+// CourierInfo enriches courier contracts for haulers
+type CourierInfo struct {
+	Route           *CourierRoute `json:"route,omitempty"`
+	IskPerJump      float64       `json:"isk_per_jump,omitempty"`
+	IskPerM3        float64       `json:"isk_per_m3,omitempty"`
+	CollateralRatio float64       `json:"collateral_ratio,omitempty"`
+}
+
+// CourierRoute is the shortest stargate route of a courier contract
+type CourierRoute struct {
+	StartSystemID int32 `json:"start_system_id"`
+	EndSystemID   int32 `json:"end_system_id"`
+	Jumps         int   `json:"jumps"`
+	HighSec       int   `json:"high_sec"`
+	LowSec        int   `json:"low_sec"`
+	NullSec       int   `json:"null_sec"`
+}
+
+// Reasons a contract left the public contract list
+const (
+	ContractAccepted   = "accepted"
+	ContractInProgress = "in_progress"
+	ContractExpired    = "expired"
+	ContractRemoved    = "removed"
+)
+
+// ContractChange Details of what changed on an contract
+// Really only price and bids can change
+type ContractChange struct {
+	ContractId  int32                                        `json:"contract_id"`
+	LocationId  int64                                        `json:"location_id"`
+	Expired     bool                                         `json:"expired,omitempty"`
+	Reason      string                                       `json:"reason,omitempty"`
+	DateExpired time.Time                                    `json:"date_expired,omitempty"`
+	Changed     bool                                         `json:"-"`
+	Bids        []esi.GetContractsPublicBidsContractId200Ok  `json:"bids,omitempty"`
+	Items       []esi.GetContractsPublicItemsContractId200Ok `json:"items,omitempty"`
+	Price       float64                                      `json:"price,omitempty"`
+	Type_       string                                       `json:"type,omitempty"`
+	TimeChanged time.Time                                    `json:"time_changed,omitempty"`
+}
+
+// SnapshotRegion is a region in a dump, at the cycle the dump holds it from.
+// Broadcasts of that cycle or earlier are already in the dump.
+type SnapshotRegion struct {
+	RegionID int64  `json:"region_id"`
+	Channel  string `json:"channel"`
+	Cycle    uint64 `json:"cycle"`
+	Count    int    `json:"count"`
+}
+
+// CycleStatus reports a market or contract worker cycle on the status channel
+type CycleStatus struct {
+	RegionID  int64     `json:"region_id"`
+	Channel   string    `json:"channel"` // market or contract
+	Cycle     uint64    `json:"cycle,omitempty"`
+	Pages     int32     `json:"pages"`
+	Additions int       `json:"additions"`
+	Changes   int       `json:"changes"`
+	Deletions int       `json:"deletions"`
+	Started   time.Time `json:"started"`
+	Duration  float64   `json:"duration_ms"`
+	Expires   time.Time `json:"expires,omitempty"`
+	NextRun   time.Time `json:"next_run,omitempty"`
+	Errors    []string  `json:"errors,omitempty"`
+}
+
+// Heartbeat is sent on the status channel while the service runs
+type Heartbeat struct {
+	ServerTime time.Time `json:"server_time"`
+}
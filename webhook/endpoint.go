@@ -0,0 +1,466 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// Retries back off exponentially up to this delay
+	maxBackoff = 10 * time.Minute
+
+	// Batches waiting to be written before the oldest is dropped
+	maxPendingBatches = 100
+
+	// Batch files queued for delivery, the rest wait in the outbox
+	queueSize = 10000
+)
+
+// Delay of the first retry, doubling with each attempt
+var minBackoff = 2 * time.Second
+
+// errRejected is a delivery the endpoint will never accept
+var errRejected = errors.New("webhook rejected the delivery")
+
+type endpoint struct {
+	Endpoint
+
+	// outbox directory of undelivered batches
+	dir    string
+	client *http.Client
+	log    *slog.Logger
+
+	// messages waiting to be batched
+	mutex   sync.Mutex
+	pending []interface{}
+	full    chan struct{}
+
+	// batch files ready to deliver
+	queue chan string
+
+	// batch files queued, being delivered or waiting to be retried, and
+	// their delivery attempts
+	fileMutex sync.Mutex
+	tracked   map[string]bool
+	attempts  map[string]int
+
+	// batch files were left in the outbox because the queue was full
+	spilled bool
+}
+
+func newEndpoint(config Endpoint, dir string) (*endpoint, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint{
+		Endpoint: config,
+		dir:      dir,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		log:      logging.For("webhook").With("endpoint", config.Name),
+		full:     make(chan struct{}, 1),
+		queue:    make(chan string, queueSize),
+		tracked:  make(map[string]bool),
+		attempts: make(map[string]int),
+	}, nil
+}
+
+// wants checks the endpoint subscription
+func (e *endpoint) wants(channel, action string) bool {
+	return contains(e.Channels, channel) && (len(e.Actions) == 0 || contains(e.Actions, action))
+}
+
+// add a message to the next batch. When the batcher falls too far behind
+// the oldest batch worth of messages is dropped.
+func (e *endpoint) add(message interface{}) {
+	e.mutex.Lock()
+	if len(e.pending) >= maxPendingBatches*e.BatchSize {
+		e.pending = e.pending[e.BatchSize:]
+		metricDeliveries.With(prometheus.Labels{"endpoint": e.Name, "result": "dropped"}).Add(float64(e.BatchSize))
+	}
+	e.pending = append(e.pending, message)
+	full := len(e.pending) >= e.BatchSize
+	e.mutex.Unlock()
+
+	if full {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *endpoint) start() {
+	for _, f := range e.outbox() {
+		e.enqueue(f)
+	}
+
+	go e.batcher(sentry.CurrentHub().Clone())
+	go e.refill(sentry.CurrentHub().Clone())
+	for i := 0; i < e.Concurrency; i++ {
+		go e.deliverer(sentry.CurrentHub().Clone())
+	}
+}
+
+// enqueue a batch file for delivery unless it already is. Never blocks: when
+// the queue is full the file waits in the outbox for refill.
+func (e *endpoint) enqueue(file string) {
+	e.fileMutex.Lock()
+	defer e.fileMutex.Unlock()
+	if e.tracked[file] {
+		return
+	}
+	select {
+	case e.queue <- file:
+		e.tracked[file] = true
+	default:
+		e.spilled = true
+	}
+}
+
+// refill the queue from the outbox once it has room for what was left there
+func (e *endpoint) refill(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#webhook-refill")
+		},
+	)
+	logger := logging.WithHub(e.log, localHub)
+
+	ticker := time.NewTicker(e.BatchInterval.Duration)
+	defer ticker.Stop()
+	for range ticker.C {
+		e.fileMutex.Lock()
+		spilled := e.spilled && len(e.queue) < cap(e.queue)/2
+		if spilled {
+			e.spilled = false
+		}
+		e.fileMutex.Unlock()
+		if !spilled {
+			continue
+		}
+
+		files, err := e.list()
+		if err != nil {
+			logger.Error("listing the outbox", logging.Error, err)
+			continue
+		}
+		for _, f := range files {
+			e.enqueue(f)
+		}
+	}
+}
+
+// batcher writes pending messages to the outbox when a batch fills or the interval passes
+func (e *endpoint) batcher(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#webhook-batcher")
+		},
+	)
+	logger := logging.WithHub(e.log, localHub)
+
+	ticker := time.NewTicker(e.BatchInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.full:
+		}
+
+		for {
+			e.mutex.Lock()
+			n := len(e.pending)
+			if n > e.BatchSize {
+				n = e.BatchSize
+			}
+			batch := e.pending[:n]
+			e.pending = e.pending[n:]
+			e.mutex.Unlock()
+
+			if len(batch) == 0 {
+				break
+			}
+			file, err := e.writeBatch(batch)
+			if err != nil {
+				logger.Error("writing a batch", logging.Error, err)
+				metricDeliveries.With(prometheus.Labels{"endpoint": e.Name, "result": "dropped"}).Add(float64(len(batch)))
+				continue
+			}
+			e.enqueue(file)
+		}
+	}
+}
+
+// writeBatch saves a batch in the outbox, returning the file name
+func (e *endpoint) writeBatch(batch []interface{}) (string, error) {
+	messages := make([]interface{}, len(batch))
+	for i, m := range batch {
+		messages[i] = resolve(m, e.Enrich)
+	}
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return "", err
+	}
+
+	// Name by creation time so the outbox replays in order
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + ".json"
+	tmp := filepath.Join(e.dir, name+".tmp")
+	if err = os.WriteFile(tmp, body, 0o600); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp, filepath.Join(e.dir, name)); err != nil {
+		return "", err
+	}
+	metricOutbox.With(prometheus.Labels{"endpoint": e.Name}).Inc()
+	return name, nil
+}
+
+// outbox lists batches left over from a previous run, oldest first
+func (e *endpoint) outbox() []string {
+	files, err := e.list()
+	if err != nil {
+		e.log.Error("listing the outbox", logging.Error, err)
+		return nil
+	}
+	if len(files) > 0 {
+		e.log.Info("requeueing undelivered batches", "batches", len(files))
+	}
+	metricOutbox.With(prometheus.Labels{"endpoint": e.Name}).Add(float64(len(files)))
+	return files
+}
+
+// list the batch files in the outbox, oldest first
+func (e *endpoint) list() ([]string, error) {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// deliverer posts queued batches, limiting the endpoint to Concurrency requests
+func (e *endpoint) deliverer(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#webhook-deliverer")
+		},
+	)
+	logger := logging.WithHub(e.log, localHub)
+
+	for file := range e.queue {
+		err := e.deliver(file)
+		switch {
+		case err == nil:
+			e.finish(file, "success")
+		case errors.Is(err, errRejected):
+			logger.Warn("delivery rejected", "batch", file, logging.Error, err)
+			e.finish(file, "rejected")
+		case e.expired(file):
+			logger.Warn("dropping an expired batch", "batch", file, "max_age", e.MaxAge.String(), logging.Error, err)
+			messages := e.size(file)
+			e.finish(file, "")
+			metricDeliveries.With(prometheus.Labels{"endpoint": e.Name, "result": "dropped"}).Add(float64(messages))
+		default:
+			metricDeliveries.With(prometheus.Labels{"endpoint": e.Name, "result": "retry"}).Inc()
+			e.retry(file)
+		}
+	}
+}
+
+// deliver a batch file
+func (e *endpoint) deliver(file string) error {
+	body, err := os.ReadFile(filepath.Join(e.dir, file)) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("%w: %s", errRejected, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %s", errRejected, err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Marketwatch-Delivery", strings.TrimSuffix(file, ".json"))
+	req.Header.Set("X-Marketwatch-Timestamp", timestamp)
+	if e.Secret != "" {
+		req.Header.Set("X-Marketwatch-Signature", "sha256="+sign(e.Secret, timestamp, body))
+	}
+
+	start := time.Now()
+	res, err := e.client.Do(req)
+	metricDeliveryTime.With(prometheus.Labels{"endpoint": e.Name}).
+		Observe(float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint:errcheck
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return nil
+	case res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests:
+		return errors.New(res.Status)
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		return fmt.Errorf("%w: %s", errRejected, res.Status)
+	default:
+		return errors.New(res.Status)
+	}
+}
+
+// sign the timestamp and body
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retry a batch after an exponential backoff
+func (e *endpoint) retry(file string) {
+	e.fileMutex.Lock()
+	e.attempts[file]++
+	attempt := e.attempts[file]
+	e.fileMutex.Unlock()
+
+	time.AfterFunc(
+		backoff(attempt), func() {
+			e.fileMutex.Lock()
+			defer e.fileMutex.Unlock()
+			select {
+			case e.queue <- file:
+			default:
+				// Wait in the outbox with the others
+				delete(e.tracked, file)
+				e.spilled = true
+			}
+		},
+	)
+}
+
+// backoff before a retry
+func backoff(attempt int) time.Duration {
+	if attempt > 20 {
+		return maxBackoff
+	}
+	delay := minBackoff << uint(attempt-1)
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// size counts the messages of a batch file
+func (e *endpoint) size(file string) int {
+	body, err := os.ReadFile(filepath.Join(e.dir, file)) //nolint:gosec
+	if err != nil {
+		return 0
+	}
+	var messages []json.RawMessage
+	if json.Unmarshal(body, &messages) != nil {
+		return 0
+	}
+	return len(messages)
+}
+
+// finish with a batch, removing it from the outbox. The result is counted
+// unless empty.
+func (e *endpoint) finish(file, result string) {
+	e.fileMutex.Lock()
+	delete(e.tracked, file)
+	delete(e.attempts, file)
+	e.fileMutex.Unlock()
+
+	err := os.Remove(filepath.Join(e.dir, file))
+	if err != nil && !os.IsNotExist(err) {
+		e.log.Error("removing a batch", "batch", file, logging.Error, err)
+	}
+	metricOutbox.With(prometheus.Labels{"endpoint": e.Name}).Dec()
+	if result != "" {
+		metricDeliveries.With(prometheus.Labels{"endpoint": e.Name, "result": result}).Inc()
+	}
+}
+
+// expired batches are older than MaxAge
+func (e *endpoint) expired(file string) bool {
+	created, err := strconv.ParseInt(strings.TrimSuffix(file, ".json"), 10, 64)
+	if err != nil {
+		return true
+	}
+	return time.Since(time.Unix(0, created)) > e.MaxAge.Duration
+}
+
+func contains(list []string, v string) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Metrics
+var (
+	metricDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "webhook",
+			Name:      "deliveries",
+			Help:      "Webhook batches by result: success, retry or rejected, and messages dropped.",
+		}, []string{"endpoint", "result"},
+	)
+
+	metricDeliveryTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "webhook",
+			Name:      "delivery",
+			Help:      "Webhook delivery statistics.",
+			Buckets:   prometheus.ExponentialBuckets(10, 1.6, 20),
+		}, []string{"endpoint"},
+	)
+
+	metricOutbox = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "webhook",
+			Name:      "outbox",
+			Help:      "Undelivered webhook batches.",
+		}, []string{"endpoint"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricDeliveries,
+		metricDeliveryTime,
+		metricOutbox,
+	)
+}
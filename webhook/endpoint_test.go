@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver records the deliveries to a test endpoint
+type receiver struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	inFlight int
+	maxIn    int
+
+	// statuses to answer with in turn, then 200
+	statuses []int
+	// delay before answering
+	delay time.Duration
+}
+
+func newReceiver(t *testing.T) *receiver {
+	rec := &receiver{}
+	rec.server = httptest.NewServer(http.HandlerFunc(rec.serve))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+func (rec *receiver) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rec.mutex.Lock()
+	rec.inFlight++
+	if rec.inFlight > rec.maxIn {
+		rec.maxIn = rec.inFlight
+	}
+	status := http.StatusOK
+	if len(rec.statuses) > 0 {
+		status, rec.statuses = rec.statuses[0], rec.statuses[1:]
+	}
+	rec.mutex.Unlock()
+
+	time.Sleep(rec.delay)
+
+	rec.mutex.Lock()
+	rec.inFlight--
+	if status == http.StatusOK {
+		rec.bodies = append(rec.bodies, string(body))
+		rec.headers = append(rec.headers, r.Header.Clone())
+	}
+	rec.mutex.Unlock()
+	w.WriteHeader(status)
+}
+
+// delivered waits for count deliveries and returns their bodies
+func (rec *receiver) delivered(t *testing.T, count int) []string {
+	var bodies []string
+	assert.Eventually(
+		t, func() bool {
+			rec.mutex.Lock()
+			defer rec.mutex.Unlock()
+			bodies = append([]string(nil), rec.bodies...)
+			return len(bodies) >= count
+		}, 5*time.Second, 5*time.Millisecond,
+	)
+	return bodies
+}
+
+func newTestEndpoint(t *testing.T, rec *receiver, config Endpoint) *endpoint {
+	config.Name = "test"
+	config.URL = rec.server.URL
+	config.Channels = []string{"market"}
+	assert.Nil(t, config.setDefaults())
+	e, err := newEndpoint(config, t.TempDir())
+	assert.Nil(t, err)
+	return e
+}
+
+func writeOutbox(t *testing.T, e *endpoint, name, body string) {
+	assert.Nil(t, os.WriteFile(filepath.Join(e.dir, name), []byte(body), 0o600))
+}
+
+func TestSignature(t *testing.T) {
+	rec := newReceiver(t)
+	e := newTestEndpoint(t, rec, Endpoint{Secret: "shh"})
+	writeOutbox(t, e, "1.json", `[1]`)
+	e.start()
+
+	rec.delivered(t, 1)
+	header := rec.headers[0]
+	assert.Equal(t, "1", header.Get("X-Marketwatch-Delivery"))
+
+	mac := hmac.New(sha256.New, []byte("shh"))
+	mac.Write([]byte(header.Get("X-Marketwatch-Timestamp") + ".[1]"))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), header.Get("X-Marketwatch-Signature"))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, minBackoff, backoff(1))
+	assert.Equal(t, 2*minBackoff, backoff(2))
+	assert.Equal(t, 8*minBackoff, backoff(4))
+	assert.Equal(t, maxBackoff, backoff(10))
+	assert.Equal(t, maxBackoff, backoff(100))
+}
+
+func TestRetry(t *testing.T) {
+	defer func(d time.Duration) { minBackoff = d }(minBackoff)
+	minBackoff = time.Millisecond
+
+	rec := newReceiver(t)
+	rec.statuses = []int{http.StatusInternalServerError, http.StatusTooManyRequests}
+	e := newTestEndpoint(t, rec, Endpoint{})
+	writeOutbox(t, e, strconv.FormatInt(time.Now().UnixNano(), 10)+".json", `[1]`)
+	e.start()
+
+	// Delivered on the third attempt and removed from the outbox
+	assert.Equal(t, []string{`[1]`}, rec.delivered(t, 1))
+	assert.Eventually(
+		t, func() bool {
+			files, _ := e.list()
+			return len(files) == 0
+		}, time.Second, time.Millisecond,
+	)
+	e.fileMutex.Lock()
+	assert.Empty(t, e.attempts)
+	assert.Empty(t, e.tracked)
+	e.fileMutex.Unlock()
+}
+
+func TestExpired(t *testing.T) {
+	rec := newReceiver(t)
+	rec.statuses = []int{http.StatusInternalServerError}
+	e := newTestEndpoint(t, rec, Endpoint{})
+	writeOutbox(t, e, "1.json", `[1]`)
+	writeOutbox(t, e, "2.json", `[2]`)
+	e.start()
+
+	// Too old to retry
+	assert.Equal(t, []string{`[2]`}, rec.delivered(t, 1))
+}
+
+func TestRejected(t *testing.T) {
+	rec := newReceiver(t)
+	rec.statuses = []int{http.StatusBadRequest}
+	e := newTestEndpoint(t, rec, Endpoint{})
+	writeOutbox(t, e, "1.json", `[1]`)
+	writeOutbox(t, e, "2.json", `[2]`)
+	e.start()
+
+	// Not retried
+	assert.Equal(t, []string{`[2]`}, rec.delivered(t, 1))
+}
+
+func TestOutboxOnStart(t *testing.T) {
+	rec := newReceiver(t)
+	e := newTestEndpoint(t, rec, Endpoint{BatchInterval: Duration{10 * time.Millisecond}})
+	writeOutbox(t, e, "2.json", `[2]`)
+	writeOutbox(t, e, "1.json", `[1]`)
+	writeOutbox(t, e, "ignored.tmp", `[3]`)
+	e.start()
+
+	// Oldest first, and the new batches after them
+	e.add(4)
+	assert.Equal(t, []string{`[1]`, `[2]`, `[4]`}, rec.delivered(t, 3))
+}
+
+func TestOutboxSpill(t *testing.T) {
+	rec := newReceiver(t)
+	e := newTestEndpoint(t, rec, Endpoint{BatchInterval: Duration{10 * time.Millisecond}})
+	e.queue = make(chan string, 2)
+	for _, name := range []string{"1.json", "2.json", "3.json", "4.json", "5.json"} {
+		writeOutbox(t, e, name, `[`+name[:1]+`]`)
+	}
+	e.start()
+
+	// What did not fit in the queue follows from the outbox
+	assert.ElementsMatch(t, []string{`[1]`, `[2]`, `[3]`, `[4]`, `[5]`}, rec.delivered(t, 5))
+}
+
+func TestConcurrency(t *testing.T) {
+	rec := newReceiver(t)
+	rec.delay = 20 * time.Millisecond
+	e := newTestEndpoint(t, rec, Endpoint{Concurrency: 2})
+	for _, name := range []string{"1.json", "2.json", "3.json", "4.json", "5.json", "6.json"} {
+		writeOutbox(t, e, name, `[]`)
+	}
+	e.start()
+
+	rec.delivered(t, 6)
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	assert.Equal(t, 2, rec.maxIn)
+}
+
+func TestPendingLimit(t *testing.T) {
+	e := &endpoint{Endpoint: Endpoint{Name: "pending", BatchSize: 2}, full: make(chan struct{}, 1)}
+	for i := 0; i < maxPendingBatches*2+1; i++ {
+		e.add(i)
+	}
+
+	// The oldest batch made room
+	assert.Len(t, e.pending, maxPendingBatches*2-1)
+	assert.Equal(t, 2, e.pending[0])
+	dropped := metricDeliveries.With(prometheus.Labels{"endpoint": "pending", "result": "dropped"})
+	assert.Equal(t, 2.0, testutil.ToFloat64(dropped))
+}
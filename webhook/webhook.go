@@ -0,0 +1,150 @@
+// Package webhook delivers broadcast messages to http endpoints in signed batches.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/wsbroadcast"
+	"github.com/getsentry/sentry-go"
+)
+
+// Option set on optional messages for endpoints that want enrichment
+const enrichOption = "enrich"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Endpoint is the configuration of a webhook
+type Endpoint struct {
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	Secret        string   `json:"secret,omitempty"`
+	Channels      []string `json:"channels"`
+	Actions       []string `json:"actions,omitempty"`
+	Enrich        bool     `json:"enrich,omitempty"`
+	Concurrency   int      `json:"concurrency,omitempty"`
+	BatchSize     int      `json:"batch_size,omitempty"`
+	BatchInterval Duration `json:"batch_interval,omitempty"`
+	MaxAge        Duration `json:"max_age,omitempty"`
+}
+
+// Duration reads a time.Duration from a string such as "5s"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses the duration string
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalJSON writes the duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (e *Endpoint) setDefaults() error {
+	if !validName.MatchString(e.Name) {
+		return fmt.Errorf("webhook name %q must be letters, numbers, - or _", e.Name)
+	}
+	if e.URL == "" {
+		return fmt.Errorf("webhook %s has no url", e.Name)
+	}
+	if len(e.Channels) == 0 {
+		return fmt.Errorf("webhook %s has no channels", e.Name)
+	}
+	if e.Concurrency < 1 {
+		e.Concurrency = 1
+	}
+	if e.BatchSize < 1 {
+		e.BatchSize = 100
+	}
+	if e.BatchInterval.Duration <= 0 {
+		e.BatchInterval.Duration = 5 * time.Second
+	}
+	if e.MaxAge.Duration <= 0 {
+		e.MaxAge.Duration = 24 * time.Hour
+	}
+	return nil
+}
+
+// Sink posts broadcasts to the configured endpoints
+type Sink struct {
+	endpoints []*endpoint
+}
+
+// NewSink reads the endpoint configuration, a json array of Endpoint,
+// and keeps undelivered batches under the outbox directory.
+func NewSink(configPath, outbox string) (*Sink, error) {
+	raw, err := os.ReadFile(configPath) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	var config []Endpoint
+	if err = json.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+
+	s := &Sink{}
+	names := make(map[string]bool)
+	for _, c := range config {
+		if err = c.setDefaults(); err != nil {
+			return nil, err
+		}
+		if names[c.Name] {
+			return nil, errors.New("duplicate webhook name " + c.Name)
+		}
+		names[c.Name] = true
+
+		e, err := newEndpoint(c, filepath.Join(outbox, c.Name))
+		if err != nil {
+			return nil, err
+		}
+		s.endpoints = append(s.endpoints, e)
+	}
+	return s, nil
+}
+
+// Run the batchers and deliveries of every endpoint
+func (s *Sink) Run(localHub *sentry.Hub) {
+	localHub.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetTag("locationHash", "go#webhook-sink")
+		},
+	)
+	for _, e := range s.endpoints {
+		e.start()
+	}
+}
+
+// Publish queues a broadcast for the endpoints subscribed to it. Never blocks.
+func (s *Sink) Publish(channel, action string, message interface{}) {
+	for _, e := range s.endpoints {
+		if e.wants(channel, action) {
+			e.add(message)
+		}
+	}
+}
+
+// resolve the form of an optional message for an endpoint
+func resolve(message interface{}, enrich bool) interface{} {
+	m, ok := message.(*wsbroadcast.OptionalMessage)
+	if !ok {
+		return message
+	}
+	return m.For(map[string]bool{enrichOption: enrich})
+}
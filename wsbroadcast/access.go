@@ -0,0 +1,172 @@
+package wsbroadcast
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+)
+
+// FilterFunc narrows a message to what a client may see. It returns nil to
+// hold the message back. Filters of broadcasts run on the hub goroutine and
+// must be quick. The channel is empty for dump messages.
+type FilterFunc func(channel string, message interface{}) interface{}
+
+// Grant is what an authorized connection may receive
+type Grant struct {
+	// Name of the credential, for audit logs. Clients with the same name
+	// share filtered messages.
+	Name string
+
+	// Allowed checks a channel may be subscribed to, all may when nil
+	Allowed func(channel string) bool
+
+	// Filter of the broadcasts and dump, nil for everything
+	Filter FilterFunc
+
+	// Release is called once the connection ends, may be nil
+	Release func()
+}
+
+// AuthorizeFunc grants a request access, or refuses it with an error.
+// Errors that are a *Refusal set the response status.
+type AuthorizeFunc func(r *http.Request) (*Grant, error)
+
+// Refusal is an authorization error with the status to answer with
+type Refusal struct {
+	Status int
+	Err    error
+}
+
+func (r *Refusal) Error() string {
+	return r.Err.Error()
+}
+
+func (r *Refusal) Unwrap() error {
+	return r.Err
+}
+
+// Authorize requires clients to be granted access by f
+func (h *Hub) Authorize(f AuthorizeFunc) {
+	h.authorize = f
+}
+
+// AllowOrigins limits browser clients to these origins, e.g.
+// https://example.com. Clients that send no Origin are not affected.
+func (h *Hub) AllowOrigins(origins ...string) {
+	for _, o := range origins {
+		h.origins = append(h.origins, strings.ToLower(strings.TrimRight(o, "/")))
+	}
+}
+
+// admit checks the origin and credentials of a request for channels,
+// answering the request when it is refused
+func (h *Hub) admit(w http.ResponseWriter, r *http.Request, channels map[string]bool) (*Grant, bool) {
+	if !h.originAllowed(r) {
+		h.audit("refused", r, nil, "reason", "origin not allowed")
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, false
+	}
+	if h.authorize == nil {
+		return nil, true
+	}
+
+	grant, err := h.authorize(r)
+	if err != nil {
+		status := http.StatusUnauthorized
+		var refusal *Refusal
+		if errors.As(err, &refusal) {
+			status = refusal.Status
+		}
+		h.audit("refused", r, grant, logging.Error, err)
+		http.Error(w, err.Error(), status)
+		return nil, false
+	}
+	if grant.Allowed != nil {
+		for c := range channels {
+			if !grant.Allowed(c) {
+				grant.release()
+				h.audit("refused", r, grant, "reason", "channel not allowed", logging.Channel, c)
+				http.Error(w, "channel "+c+" not allowed", http.StatusForbidden)
+				return nil, false
+			}
+		}
+	}
+	return grant, true
+}
+
+// originAllowed when no allowlist is set, the request has no Origin or the
+// Origin is on the list
+func (h *Hub) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(h.origins) == 0 || origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	origin = strings.ToLower(u.Scheme + "://" + u.Host)
+	for _, o := range h.origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// release the grant of a connection
+func (g *Grant) release() {
+	if g != nil && g.Release != nil {
+		g.Release()
+	}
+}
+
+// name of the grant for logs
+func (g *Grant) name() string {
+	if g == nil {
+		return "-"
+	}
+	return g.Name
+}
+
+// filtered form of a broadcast for the client's grant. Clients with the
+// same grant name share the result through seen.
+func (c *Client) filtered(m sequenced, seen map[string]sequenced) (sequenced, bool) {
+	if c.grant == nil || c.grant.Filter == nil {
+		return m, true
+	}
+	if f, ok := seen[c.grant.Name]; ok {
+		return f, f.message != nil
+	}
+	f := m
+	f.message = c.grant.Filter(m.channel, m.message)
+	f.cache = newPreparedCache()
+	if seen != nil {
+		seen[c.grant.Name] = f
+	}
+	return f, f.message != nil
+}
+
+// audit logs who connected, disconnected or was refused
+func (h *Hub) audit(event string, r *http.Request, grant *Grant, args ...any) {
+	h.log.Info(
+		"audit",
+		append(
+			[]any{"event", event, "key", grant.name(), logging.ClientAddr, r.RemoteAddr, "origin", r.Header.Get("Origin")},
+			args...,
+		)...,
+	)
+}
+
+// auditDisconnect logs the end of a connection
+func auditDisconnect(c *Client, reason string) {
+	c.log.Info(
+		"audit",
+		"event", "disconnect", "key", c.grant.name(), "after", time.Since(c.connected).Round(time.Second).String(),
+		"reason", reason,
+	)
+}
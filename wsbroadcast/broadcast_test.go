@@ -1,15 +1,19 @@
 package wsbroadcast
 
 import (
+	"bufio"
+	"errors"
 	"log"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestWebSocket(t *testing.T) {
@@ -56,3 +60,248 @@ func TestWebSocket(t *testing.T) {
 	err = c.Close()
 	assert.Nil(t, err)
 }
+
+func TestServerSentEvents(t *testing.T) {
+	hub := NewHub([]string{"market"})
+	hub.KeepHistory(10)
+	hub.OnRegister(
+		func(subs map[string]bool, send chan interface{}) {
+			send <- "dump"
+		},
+	)
+	go hub.Run(sentry.CurrentHub().Clone())
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Nil(t, hub.ServeSSE(w, r))
+			},
+		),
+	)
+	defer server.Close()
+
+	read := func(lastEventID string, lines int) []string {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/?market=1", nil)
+		assert.Nil(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer res.Body.Close()
+
+		var got []string
+		scanner := bufio.NewScanner(res.Body)
+		for len(got) < lines && scanner.Scan() {
+			if scanner.Text() != "" {
+				got = append(got, scanner.Text())
+			}
+		}
+		return got
+	}
+
+	// A new client gets the dump and where it is up to
+	assert.Equal(t, []string{`data: "dump"`, "id: " + hub.epoch + "-0"}, read("", 2))
+
+	hub.Broadcast("market", "one")
+	hub.Broadcast("market", "two")
+
+	// A resuming client gets what it missed instead of the dump
+	assert.Equal(t, []string{"id: " + hub.epoch + "-2", `data: "two"`}, read(hub.epoch+"-1", 2))
+
+	// IDs from before a restart, or without an epoch, start over
+	assert.Equal(t, []string{`data: "dump"`, "id: " + hub.epoch + "-2"}, read("earlier-1", 2))
+	assert.Equal(t, []string{`data: "dump"`, "id: " + hub.epoch + "-2"}, read("1", 2))
+}
+
+func TestEncodings(t *testing.T) {
+	hub := NewHub([]string{"market"})
+	hub.OnRegister(
+		func(subs map[string]bool, send chan interface{}) {
+			send <- map[string]int{"order_id": 1}
+		},
+	)
+	go hub.Run(sentry.CurrentHub().Clone())
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Nil(t, hub.ServeWs(w, r))
+			},
+		),
+	)
+	defer server.Close()
+	u := "ws" + server.URL[len("http"):] + "/?market=1"
+
+	// Negotiated with a subprotocol
+	dialer := websocket.Dialer{Subprotocols: []string{"msgpack"}}
+	c, _, err := dialer.Dial(u, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "msgpack", c.Subprotocol())
+	messageType, data, err := c.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	var message map[string]int
+	assert.Nil(t, msgpack.Unmarshal(data, &message))
+	assert.Equal(t, 1, message["order_id"])
+	assert.Nil(t, c.Close())
+
+	// Or with the query
+	c, _, err = websocket.DefaultDialer.Dial(u+"&encoding=cbor", nil)
+	assert.Nil(t, err)
+	messageType, _, err = c.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Nil(t, c.Close())
+
+	// Unknown encodings are refused
+	_, res, err := websocket.DefaultDialer.Dial(u+"&encoding=xml", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPreparedOnce(t *testing.T) {
+	m := sequenced{
+		seq:     1,
+		channel: "market",
+		message: NewOptionalMessage(
+			"enrich", "plain", func() interface{} {
+				return "enriched"
+			},
+		),
+		cache: newPreparedCache(),
+	}
+	plain := &Client{encoding: JSON}
+	also := &Client{encoding: JSON}
+	enriched := &Client{encoding: JSON, options: map[string]bool{"enrich": true}}
+	binary := &Client{encoding: MessagePack}
+
+	// Clients wanting the same form share its encoding
+	assert.Same(t, plain.prepared(m), also.prepared(m))
+	assert.Equal(t, `"plain"`, string(plain.prepared(m).data))
+	assert.Equal(t, `"enriched"`, string(enriched.prepared(m).data))
+	assert.NotSame(t, plain.prepared(m), binary.prepared(m))
+	assert.Len(t, m.cache.entries, 3)
+}
+
+func TestDumpDoesNotBlock(t *testing.T) {
+	hub := NewHub([]string{"market", "slow"})
+	entered := make(chan struct{})
+	gate := make(chan struct{})
+	hub.OnRegister(
+		func(subs map[string]bool, send chan interface{}) {
+			if subs["slow"] {
+				entered <- struct{}{}
+				<-gate
+			}
+			send <- "dump"
+		},
+	)
+	go hub.Run(sentry.CurrentHub().Clone())
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Nil(t, hub.ServeWs(w, r))
+			},
+		),
+	)
+	defer server.Close()
+	u := "ws" + server.URL[len("http"):] + "/?market=1"
+
+	read := func(c *websocket.Conn) string {
+		message := ""
+		assert.Nil(t, c.ReadJSON(&message))
+		return message
+	}
+
+	fast, _, err := websocket.DefaultDialer.Dial(u, nil)
+	assert.Nil(t, err)
+	defer fast.Close()
+	assert.Equal(t, "dump", read(fast))
+
+	slow, _, err := websocket.DefaultDialer.Dial(u+"&slow=1", nil)
+	assert.Nil(t, err)
+	defer slow.Close()
+	<-entered
+
+	// Broadcasts carry on while the slow client is dumping
+	hub.Broadcast("market", "live")
+	assert.Equal(t, "live", read(fast))
+
+	// And reach it once its dump is done
+	close(gate)
+	assert.Equal(t, "dump", read(slow))
+	assert.Equal(t, "live", read(slow))
+}
+
+func TestAuthorize(t *testing.T) {
+	hub := NewHub([]string{"market", "contract"})
+	hub.AllowOrigins("https://example.com")
+	hub.Authorize(
+		func(r *http.Request) (*Grant, error) {
+			if r.URL.Query().Get("key") != "secret" {
+				return nil, &Refusal{Status: http.StatusUnauthorized, Err: errors.New("no")}
+			}
+			return &Grant{
+				Name:    "team",
+				Allowed: func(c string) bool { return c == "market" },
+				Filter: func(channel string, m interface{}) interface{} {
+					if m == "hidden" {
+						return nil
+					}
+					return m
+				},
+			}, nil
+		},
+	)
+	hub.OnRegister(
+		func(subs map[string]bool, send chan interface{}) {
+			send <- "hidden"
+			send <- "dump"
+		},
+	)
+	go hub.Run(sentry.CurrentHub().Clone())
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Nil(t, hub.ServeWs(w, r))
+			},
+		),
+	)
+	defer server.Close()
+	u := "ws" + server.URL[len("http"):] + "/?market=1"
+
+	dial := func(query string, origin string) (*websocket.Conn, int) {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		c, res, err := websocket.DefaultDialer.Dial(u+query, header)
+		if err != nil {
+			return nil, res.StatusCode
+		}
+		return c, http.StatusSwitchingProtocols
+	}
+
+	_, status := dial("", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	_, status = dial("&key=secret&contract=1", "")
+	assert.Equal(t, http.StatusForbidden, status)
+	_, status = dial("&key=secret", "https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	c, status := dial("&key=secret", "https://example.com")
+	assert.Equal(t, http.StatusSwitchingProtocols, status)
+	defer c.Close()
+
+	// The filter applies to the dump and broadcasts
+	message := ""
+	assert.Nil(t, c.ReadJSON(&message))
+	assert.Equal(t, "dump", message)
+	hub.Broadcast("market", "hidden")
+	hub.Broadcast("market", "live")
+	assert.Nil(t, c.ReadJSON(&message))
+	assert.Equal(t, "live", message)
+}
@@ -1,27 +1,71 @@
 package wsbroadcast
 
 import (
-	"log"
+	"sync"
 	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
 	"github.com/getsentry/sentry-go"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
 )
 
 var zeroTime time.Time
 
+const (
+	// Messages of the dump buffered ahead of the client
+	dumpBuffer = 16
+
+	// Broadcasts held back while a client receives its dump. Clients
+	// falling further behind are dropped.
+	maxBacklog = 4096
+)
+
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
 	hub *Hub
 
-	// The websocket connection.
+	// The websocket connection, nil for server-sent events.
 	conn *websocket.Conn
 
+	// Logs with the remote address
+	log *slog.Logger
+
 	// Buffered channel of outbound messages.
 	send chan interface{}
 
+	// The dump and the broadcasts held back during it, read before send.
+	// Closed when done, then left to nil by the writer.
+	dump chan interface{}
+
+	// Broadcasts held back while dumping
+	backlogMutex sync.Mutex
+	dumping      bool
+	backlog      []interface{}
+
 	// Channels available to the client
 	channels map[string]bool
+
+	// Options the client connected with
+	options map[string]bool
+
+	// How messages are written to the websocket
+	encoding *Encoding
+
+	// What the client was granted, nil without authorization
+	grant     *Grant
+	connected time.Time
+
+	// Dump on its way through the grant's filter
+	dumpFilter   chan interface{}
+	dumpFiltered chan struct{}
+
+	// Bytes of the dump written so far
+	dumpBytes int
+
+	// Sequence of the last broadcast the client saw before reconnecting
+	resumeFrom uint64
 }
 
 // CanSend checks if the client is subscribed to a channel
@@ -29,6 +73,75 @@ func (c *Client) CanSend(channel string) bool {
 	return c.channels[channel]
 }
 
+// hold back a broadcast while the client receives its dump. Reports if it
+// was held, or if the backlog is full.
+func (c *Client) hold(m interface{}) (bool, bool) {
+	c.backlogMutex.Lock()
+	defer c.backlogMutex.Unlock()
+	if !c.dumping {
+		return false, false
+	}
+	if len(c.backlog) >= maxBacklog {
+		return false, true
+	}
+	c.backlog = append(c.backlog, m)
+	return true, false
+}
+
+// incoming messages for the client, from the dump until it is done and
+// then from send. Only the writer may call it.
+func (c *Client) incoming() chan interface{} {
+	if c.dump != nil {
+		return c.dump
+	}
+	return c.send
+}
+
+// closed moves on from a closed incoming channel, reporting if it was send
+func (c *Client) closed() bool {
+	if c.dump != nil {
+		c.dump = nil
+		metricDumpTime.Observe(float64(time.Since(c.connected).Nanoseconds()) / float64(time.Millisecond))
+		metricDumpBytes.Observe(float64(c.dumpBytes))
+		return false
+	}
+	return true
+}
+
+// sent counts a message written to the client
+func (c *Client) sent(message interface{}, bytes int) {
+	channel := "dump"
+	if m, ok := message.(sequenced); ok {
+		channel = m.channel
+	} else if c.dump != nil {
+		c.dumpBytes += bytes
+	}
+	labels := prometheus.Labels{"channel": channel, "action": c.hub.action(message)}
+	metricMessagesSent.With(labels).Inc()
+	metricBytesSent.With(labels).Add(float64(bytes))
+}
+
+// drain the dump when the writer gives up, so that it can finish
+func (c *Client) drain() {
+	if c.dump != nil {
+		go func(dump chan interface{}) {
+			for range dump {
+			}
+		}(c.dump)
+	}
+}
+
+// unwrap a message from the send channel into what is written to the client
+func (c *Client) unwrap(message interface{}) interface{} {
+	if m, ok := message.(sequenced); ok {
+		message = m.message
+	}
+	if m, ok := message.(*OptionalMessage); ok {
+		message = m.For(c.options)
+	}
+	return message
+}
+
 // readPump pumps messages from the websocket connection to the hub.
 //
 // The application runs readPump in a per-connection goroutine. The application
@@ -40,30 +153,29 @@ func (c *Client) readPump(localHub *sentry.Hub) {
 			scope.SetTag("locationHash", "go#read-pump")
 		},
 	)
+	logger := logging.WithHub(c.log, localHub)
 
 	defer func() {
 		c.hub.unregister <- c
 		err := c.conn.Close()
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			logger.Error("closing the connection", logging.Error, err)
 		}
 	}()
 	c.conn.SetReadLimit(1)
 	err := c.conn.SetReadDeadline(zeroTime)
 	if err != nil {
-		sentry.CaptureException(err)
-		log.Println(err)
+		logger.Error("setting the read deadline", logging.Error, err)
 	}
 
 	for {
 		// /dev/null
 		_, _, err := c.conn.ReadMessage()
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+				logger.Error("unexpected close", logging.Error, err)
+			} else {
+				logger.Debug("connection closed", logging.Error, err)
 			}
 			break
 		}
@@ -81,38 +193,64 @@ func (c *Client) writePump(localHub *sentry.Hub) {
 			scope.SetTag("locationHash", "go#write-pump")
 		},
 	)
+	logger := logging.WithHub(c.log, localHub)
 
 	defer func() {
+		c.drain()
 		err := c.conn.Close()
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			logger.Error("closing the connection", logging.Error, err)
 		}
 	}()
 
 	for {
-		message, ok := <-c.send
+		message, ok := <-c.incoming()
+		if !ok && !c.closed() {
+			continue
+		}
 
 		err := c.conn.SetWriteDeadline(zeroTime)
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			logger.Error("setting the write deadline", logging.Error, err)
 		}
 
 		if !ok {
 			err = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 			if err != nil {
-				sentry.CaptureException(err)
-				log.Println(err)
+				logger.Error("writing the close message", logging.Error, err)
 			}
 			return
 		}
 
-		err = c.conn.WriteJSON(message)
+		// Only server-sent events use sync points
+		if _, ok := message.(syncPoint); ok {
+			continue
+		}
+
+		err = c.write(message)
 		if err != nil {
-			sentry.CaptureException(err)
-			log.Println(err)
+			logger.Error("writing a message", logging.Error, err)
 			return
 		}
 	}
 }
+
+// write a message from the send channel to the websocket. Broadcasts are
+// encoded once and shared, other messages are encoded for this client.
+func (c *Client) write(message interface{}) error {
+	if m, ok := message.(sequenced); ok {
+		entry := c.prepared(m)
+		if entry.err != nil {
+			return entry.err
+		}
+		c.sent(message, len(entry.data))
+		return c.conn.WritePreparedMessage(entry.prepared)
+	}
+
+	data, err := c.encoding.encode(c.unwrap(message))
+	if err != nil {
+		return err
+	}
+	c.sent(message, len(data))
+	return c.conn.WriteMessage(c.encoding.MessageType, data)
+}
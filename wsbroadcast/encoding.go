@@ -0,0 +1,91 @@
+package wsbroadcast
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoding serializes messages for websocket clients. Every encoding maps
+// struct fields by their json tags, so a message has the same field names
+// in all of them.
+type Encoding struct {
+	// Name used as the websocket subprotocol and the encoding query parameter
+	Name string
+
+	// websocket.TextMessage or websocket.BinaryMessage
+	MessageType int
+
+	Marshal func(v interface{}) ([]byte, error)
+}
+
+// JSON is the default encoding
+var JSON = &Encoding{
+	Name:        "json",
+	MessageType: websocket.TextMessage,
+	Marshal:     json.Marshal,
+}
+
+// MessagePack encodes times with the msgpack timestamp extension
+var MessagePack = &Encoding{
+	Name:        "msgpack",
+	MessageType: websocket.BinaryMessage,
+	Marshal: func(v interface{}) ([]byte, error) {
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+}
+
+// cborMode encodes times as tagged epoch seconds, with fractions when needed
+var cborMode, _ = cbor.EncOptions{
+	Time:    cbor.TimeUnixDynamic,
+	TimeTag: cbor.EncTagRequired,
+}.EncMode()
+
+// CBOR encodes times with tag 1
+var CBOR = &Encoding{
+	Name:        "cbor",
+	MessageType: websocket.BinaryMessage,
+	Marshal:     cborMode.Marshal,
+}
+
+// Encodings clients can choose from
+var Encodings = []*Encoding{JSON, MessagePack, CBOR}
+
+// encodingNames as websocket subprotocols
+func encodingNames() []string {
+	names := make([]string, len(Encodings))
+	for i, e := range Encodings {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// encodingByName or nil
+func encodingByName(name string) *Encoding {
+	for _, e := range Encodings {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// queryEncoding from the encoding query parameter, JSON when there is none
+// and nil for an unknown encoding
+func queryEncoding(r *http.Request) *Encoding {
+	name := r.URL.Query().Get("encoding")
+	if name == "" {
+		return JSON
+	}
+	return encodingByName(name)
+}
@@ -1,22 +1,45 @@
 package wsbroadcast
 
 import (
-	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
+	"github.com/contorno/eve-marketwatch/logging"
 	"github.com/getsentry/sentry-go"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
 )
 
 // HandlerFunc is used for callbacks
-// sends a list of channels the client registered to and a return channel
+// sends a list of channels the client registered to and a return channel.
+// Handlers run outside the hub goroutine and may block on the channel.
 type HandlerFunc func(map[string]bool, chan interface{})
 
+// BroadcastFunc is used for broadcast callbacks
+// sends the channel and the message being broadcast
+type BroadcastFunc func(string, interface{})
+
 type fullMessage struct {
 	Channel string
 	Message interface{}
 }
 
+// syncPoint marks the broadcast sequence a client is up to date with
+type syncPoint uint64
+
+// sequenced is a broadcast with its position in the hub history
+type sequenced struct {
+	seq     uint64
+	channel string
+	message interface{}
+
+	// encoded forms shared by the clients
+	cache *preparedCache
+}
+
 // Hub maintains the set of active clients and broadcasts messages to the
 // clients.
 type Hub struct {
@@ -35,8 +58,35 @@ type Hub struct {
 	// onRegister callbacks
 	onRegister []HandlerFunc
 
+	// onBroadcast callbacks
+	onBroadcast []BroadcastFunc
+
 	// which channels are available to register for
 	channels []string
+
+	// which options clients can set
+	options []string
+
+	// sequence number of the last broadcast, and the epoch it counts in.
+	// The sequence starts over with each process, so event IDs carry the
+	// epoch to tell them apart.
+	seq   uint64
+	epoch string
+
+	// recent broadcasts for clients resuming, oldest first
+	history     []sequenced
+	historySize int
+
+	// grants access to clients, everyone may connect when nil
+	authorize AuthorizeFunc
+
+	// browser origins allowed to connect, any when empty
+	origins []string
+
+	// names the action of a message for metrics
+	actionOf func(interface{}) string
+
+	log *slog.Logger
 }
 
 // NewHub Create a new hub for the handler
@@ -47,8 +97,9 @@ func NewHub(availableChannels []string) *Hub {
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
 		channels:   availableChannels,
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		log:        slog.Default(),
 	}
-	log.Printf("starting hub. available channels: %v\n", availableChannels)
 	return hub
 }
 
@@ -57,11 +108,51 @@ func (h *Hub) Broadcast(channel string, m interface{}) {
 	h.broadcast <- fullMessage{channel, m}
 }
 
-// OnRegister calls a handler when a client registers.
+// AddOptions makes options available to clients. Options are set with query
+// parameters like channels, and select the form of an OptionalMessage.
+func (h *Hub) AddOptions(options ...string) {
+	h.options = append(h.options, options...)
+}
+
+// KeepHistory keeps the last n broadcasts so that clients reconnecting
+// within them can resume instead of receiving the register handlers' dump.
+func (h *Hub) KeepHistory(n int) {
+	h.historySize = n
+}
+
+// SetLogger logs to l instead of the default logger
+func (h *Hub) SetLogger(l *slog.Logger) {
+	h.log = l
+}
+
+// DescribeActions names the action of messages in metrics
+func (h *Hub) DescribeActions(f func(message interface{}) string) {
+	h.actionOf = f
+}
+
+// action of a message for metrics
+func (h *Hub) action(message interface{}) string {
+	if m, ok := message.(sequenced); ok {
+		message = m.message
+	}
+	if h.actionOf == nil {
+		return ""
+	}
+	return h.actionOf(message)
+}
+
+// OnRegister calls a handler when a client registers. Broadcasts are held
+// back from the client until the handlers return.
 func (h *Hub) OnRegister(f HandlerFunc) {
 	h.onRegister = append(h.onRegister, f)
 }
 
+// OnBroadcast calls a handler with every broadcast message, from the hub
+// goroutine. Handlers must not block.
+func (h *Hub) OnBroadcast(f BroadcastFunc) {
+	h.onBroadcast = append(h.onBroadcast, f)
+}
+
 // Run the websocket handler
 func (h *Hub) Run(localHub *sentry.Hub) {
 	localHub.ConfigureScope(
@@ -70,30 +161,185 @@ func (h *Hub) Run(localHub *sentry.Hub) {
 		},
 	)
 
+	logging.WithHub(h.log, localHub).Info("starting hub", "channels", h.channels)
 	for {
 		select {
 		case client := <-h.register:
 			h.clients[client] = true
-			for _, c := range h.onRegister {
-				c(client.channels, client.send)
-				log.Printf("registered %s to channels %v\n", client.conn.RemoteAddr(), client.channels)
+			metricRegistrations.Inc()
+			for c := range client.channels {
+				metricClients.With(prometheus.Labels{"channel": c}).Inc()
 			}
+			missed, resumed := h.missed(client)
+			go h.dump(client, missed, resumed, h.seq)
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
-				log.Printf("unregistering %s\n", client.conn.RemoteAddr())
-				delete(h.clients, client)
-				close(client.send)
+				client.log.Debug("unregistering")
+				h.remove(client, "closed")
 			}
 		case message := <-h.broadcast:
+			for _, f := range h.onBroadcast {
+				f(message.Channel, message.Message)
+			}
+
+			h.seq++
+			m := sequenced{
+				seq:     h.seq,
+				channel: message.Channel,
+				message: message.Message,
+				cache:   newPreparedCache(),
+			}
+			if h.historySize > 0 {
+				if len(h.history) >= h.historySize {
+					h.history = h.history[1:]
+				}
+				h.history = append(h.history, m)
+			}
+
+			seen := make(map[string]sequenced)
 			for client := range h.clients {
-				if client.CanSend(message.Channel) {
-					client.send <- message.Message
-					close(client.send)
-					delete(h.clients, client)
+				if !client.CanSend(message.Channel) {
+					continue
+				}
+				m, ok := client.filtered(m, seen)
+				if !ok {
+					continue
+				}
+				held, full := client.hold(m)
+				if held {
+					continue
+				}
+				if !full {
+					metricSendQueue.Observe(float64(len(client.send)))
+					select {
+					case client.send <- m:
+						continue
+					default:
+					}
+				}
+				// Drop clients that can not keep up
+				h.remove(client, "too slow")
+			}
+		}
+	}
+}
+
+// remove a client from the hub, closing its send channel
+func (h *Hub) remove(client *Client, reason string) {
+	delete(h.clients, client)
+	close(client.send)
+	client.grant.release()
+	auditDisconnect(client, reason)
+	metricUnregistrations.With(prometheus.Labels{"reason": reason}).Inc()
+	for c := range client.channels {
+		metricClients.With(prometheus.Labels{"channel": c}).Dec()
+	}
+}
+
+// dumpInput is the channel register handlers send the dump to, filtered
+// for the client's grant on its way to the dump channel
+func (c *Client) dumpInput() chan interface{} {
+	if c.grant == nil || c.grant.Filter == nil {
+		return c.dump
+	}
+	if c.dumpFilter == nil {
+		c.dumpFilter = make(chan interface{}, dumpBuffer)
+		c.dumpFiltered = make(chan struct{})
+		go func() {
+			defer close(c.dumpFiltered)
+			for m := range c.dumpFilter {
+				if m = c.grant.Filter("", m); m != nil {
+					c.dump <- m
 				}
 			}
+		}()
+	}
+	return c.dumpFilter
+}
+
+// dumpDone waits for the filtered dump to be passed on
+func (c *Client) dumpDone() {
+	if c.dumpFilter != nil {
+		close(c.dumpFilter)
+		<-c.dumpFiltered
+	}
+}
+
+// missed broadcasts of a resuming client, if they are all in the history
+func (h *Hub) missed(client *Client) ([]sequenced, bool) {
+	if client.resumeFrom == 0 || client.resumeFrom > h.seq || len(h.history) == 0 {
+		return nil, false
+	}
+	if client.resumeFrom < h.history[0].seq-1 {
+		// Missed more than we kept
+		return nil, false
+	}
+	var missed []sequenced
+	for _, m := range h.history {
+		if m.seq > client.resumeFrom && client.CanSend(m.channel) {
+			missed = append(missed, m)
+		}
+	}
+	return missed, true
+}
+
+// dump runs in its own goroutine for each new client, so that slow clients
+// hold up nobody else. It sends the broadcasts a resuming client missed, or
+// else the register handlers' dump, followed by the broadcasts held back
+// while it ran.
+func (h *Hub) dump(client *Client, missed []sequenced, resumed bool, seq uint64) {
+	if resumed {
+		for _, m := range missed {
+			if m, ok := client.filtered(m, nil); ok {
+				client.dump <- m
+			}
+		}
+		client.log.Info("resumed", "channels", channelList(client.channels), "from", client.resumeFrom)
+	} else {
+		for _, c := range h.onRegister {
+			c(client.channels, client.dumpInput())
+		}
+		client.dumpDone()
+		// The dump is current up to at least this broadcast
+		client.dump <- syncPoint(seq)
+		client.log.Info("registered", "channels", channelList(client.channels))
+	}
+
+	for {
+		client.backlogMutex.Lock()
+		backlog := client.backlog
+		client.backlog = nil
+		if len(backlog) == 0 {
+			// The hub sends straight to the client from now on
+			client.dumping = false
+			client.backlogMutex.Unlock()
+			break
+		}
+		client.backlogMutex.Unlock()
+
+		for _, m := range backlog {
+			client.dump <- m
+		}
+	}
+	close(client.dump)
+}
+
+// subscriptions reads the channels and options requested in the query
+func (h *Hub) subscriptions(r *http.Request) (map[string]bool, map[string]bool) {
+	channels := make(map[string]bool)
+	for _, c := range h.channels {
+		if r.URL.Query().Get(c) != "" {
+			channels[c] = true
+		}
+	}
+
+	options := make(map[string]bool)
+	for _, o := range h.options {
+		if r.URL.Query().Get(o) != "" {
+			options[o] = true
 		}
 	}
+	return channels, options
 }
 
 var upgrader = websocket.Upgrader{
@@ -103,29 +349,49 @@ var upgrader = websocket.Upgrader{
 		return true
 	},
 	EnableCompression: true,
+	Subprotocols:      encodingNames(),
 }
 
 // ServeWs handles websocket requests from the peer.
+// The encoding is negotiated with a subprotocol, or else the encoding query
+// parameter.
 func (h *Hub) ServeWs(w http.ResponseWriter, r *http.Request) error {
+	encoding := queryEncoding(r)
+	if encoding == nil {
+		http.Error(w, "unknown encoding", http.StatusBadRequest)
+		return nil
+	}
+
+	// get a list of subscription requests
+	channels, options := h.subscriptions(r)
+	grant, ok := h.admit(w, r, channels)
+	if !ok {
+		return nil
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		grant.release()
 		return err
 	}
-
-	// get a list of subscription requests
-	channels := make(map[string]bool)
-	for _, c := range h.channels {
-		if r.URL.Query().Get(c) != "" {
-			channels[c] = true
-		}
+	if e := encodingByName(conn.Subprotocol()); e != nil {
+		encoding = e
 	}
+	h.audit("connect", r, grant, "channels", channelList(channels), "encoding", encoding.Name)
 
 	// Create a new client
 	client := &Client{
-		hub:      h,
-		conn:     conn,
-		send:     make(chan interface{}, 256),
-		channels: channels,
+		hub:       h,
+		conn:      conn,
+		log:       h.log.With(logging.ClientAddr, conn.RemoteAddr().String()),
+		send:      make(chan interface{}, 256),
+		dump:      make(chan interface{}, dumpBuffer),
+		dumping:   true,
+		channels:  channels,
+		options:   options,
+		encoding:  encoding,
+		grant:     grant,
+		connected: time.Now(),
 	}
 
 	client.hub.register <- client
@@ -134,3 +400,13 @@ func (h *Hub) ServeWs(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+// channelList of a subscription, sorted for logs
+func channelList(channels map[string]bool) []string {
+	list := make([]string, 0, len(channels))
+	for c := range channels {
+		list = append(list, c)
+	}
+	sort.Strings(list)
+	return list
+}
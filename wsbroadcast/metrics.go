@@ -0,0 +1,117 @@
+package wsbroadcast
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics
+var (
+	metricEncodeTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "encode",
+			Help:      "Message encoding statistics, in milliseconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 20),
+		}, []string{"encoding"},
+	)
+
+	metricEncodeBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "encoded_bytes",
+			Help:      "Bytes of encoded messages, before compression.",
+		}, []string{"encoding"},
+	)
+
+	metricClients = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "clients",
+			Help:      "Connected clients by channel.",
+		}, []string{"channel"},
+	)
+
+	metricRegistrations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "registrations",
+			Help:      "Clients registered.",
+		},
+	)
+
+	metricUnregistrations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "unregistrations",
+			Help:      "Clients unregistered by reason: closed or too slow.",
+		}, []string{"reason"},
+	)
+
+	metricSendQueue = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "send_queue",
+			Help:      "Messages waiting for a client when a broadcast is queued for it.",
+			Buckets:   []float64{0, 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096},
+		},
+	)
+
+	metricMessagesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "messages_sent",
+			Help:      "Messages written to clients by channel and action. Dump messages have the channel dump.",
+		}, []string{"channel", "action"},
+	)
+
+	metricBytesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "bytes_sent",
+			Help:      "Bytes written to clients by channel and action, before compression.",
+		}, []string{"channel", "action"},
+	)
+
+	metricDumpTime = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "dump",
+			Help:      "Time from connecting to the end of the dump, in milliseconds.",
+			Buckets:   prometheus.ExponentialBuckets(10, 1.6, 20),
+		},
+	)
+
+	metricDumpBytes = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "evemarketwatch",
+			Subsystem: "wsbroadcast",
+			Name:      "dump_bytes",
+			Help:      "Size of dumps, before compression.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 12),
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricEncodeTime,
+		metricEncodeBytes,
+		metricClients,
+		metricRegistrations,
+		metricUnregistrations,
+		metricSendQueue,
+		metricMessagesSent,
+		metricBytesSent,
+		metricDumpTime,
+		metricDumpBytes,
+	)
+}
@@ -0,0 +1,53 @@
+package wsbroadcast
+
+import "sync"
+
+// OptionalMessage is a message with an alternate form for clients that
+// connected with an option set. The alternate is built once, on first use.
+type OptionalMessage struct {
+	option    string
+	plain     interface{}
+	build     func() interface{}
+	once      sync.Once
+	alternate interface{}
+}
+
+// NewOptionalMessage wraps a message with a builder for its alternate form
+func NewOptionalMessage(option string, plain interface{}, build func() interface{}) *OptionalMessage {
+	return &OptionalMessage{
+		option: option,
+		plain:  plain,
+		build:  build,
+	}
+}
+
+// variant names the form For picks, for caching encoded forms
+func (m *OptionalMessage) variant(options map[string]bool) string {
+	if options[m.option] {
+		return m.option
+	}
+	return ""
+}
+
+// For picks the form of the message for a set of options
+func (m *OptionalMessage) For(options map[string]bool) interface{} {
+	if !options[m.option] {
+		return m.plain
+	}
+	m.once.Do(
+		func() {
+			m.alternate = m.build()
+		},
+	)
+	return m.alternate
+}
+
+// Map passes both forms of the message through f. The alternate is still
+// only built when a client wants it.
+func (m *OptionalMessage) Map(f func(interface{}) interface{}) *OptionalMessage {
+	return NewOptionalMessage(
+		m.option, f(m.plain), func() interface{} {
+			return f(m.For(map[string]bool{m.option: true}))
+		},
+	)
+}
@@ -0,0 +1,68 @@
+package wsbroadcast
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// preparedCache holds the encoded forms of one broadcast, so that it is
+// serialized and compressed once per encoding however many clients get it.
+type preparedCache struct {
+	mutex   sync.Mutex
+	entries map[string]*preparedEntry
+}
+
+type preparedEntry struct {
+	once     sync.Once
+	data     []byte
+	prepared *websocket.PreparedMessage
+	err      error
+}
+
+func newPreparedCache() *preparedCache {
+	return &preparedCache{entries: make(map[string]*preparedEntry)}
+}
+
+// prepared form of a broadcast for a client, encoding it on first use
+func (c *Client) prepared(m sequenced) *preparedEntry {
+	key := c.encoding.Name
+	if o, ok := m.message.(*OptionalMessage); ok {
+		key += "+" + o.variant(c.options)
+	}
+
+	m.cache.mutex.Lock()
+	entry, ok := m.cache.entries[key]
+	if !ok {
+		entry = &preparedEntry{}
+		m.cache.entries[key] = entry
+	}
+	m.cache.mutex.Unlock()
+
+	entry.once.Do(
+		func() {
+			entry.data, entry.err = c.encoding.encode(c.unwrap(m))
+			if entry.err != nil {
+				return
+			}
+			// Compressed frames are built and kept on first write
+			entry.prepared, entry.err = websocket.NewPreparedMessage(c.encoding.MessageType, entry.data)
+		},
+	)
+	return entry
+}
+
+// encode a message, recording how long it took and its size
+func (e *Encoding) encode(v interface{}) ([]byte, error) {
+	start := time.Now()
+	data, err := e.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	metricEncodeTime.With(prometheus.Labels{"encoding": e.Name}).
+		Observe(float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond))
+	metricEncodeBytes.With(prometheus.Labels{"encoding": e.Name}).Add(float64(len(data)))
+	return data, nil
+}
@@ -0,0 +1,137 @@
+package wsbroadcast
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/contorno/eve-marketwatch/logging"
+)
+
+// How often idle server-sent event streams get a comment
+const keepAliveInterval = 15 * time.Second
+
+// ServeSSE streams the same channels as ServeWs as server-sent events.
+// Each event is one json message with the hub epoch and broadcast sequence
+// as its ID, so clients reconnecting with Last-Event-ID resume where they
+// left off when the hub still has the broadcasts they missed. IDs of another
+// epoch, from before a restart, get a fresh dump.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return errors.New("server-sent events need a flushing response writer")
+	}
+
+	channels, options := h.subscriptions(r)
+	grant, ok := h.admit(w, r, channels)
+	if !ok {
+		return nil
+	}
+	h.audit("connect", r, grant, "channels", channelList(channels), "encoding", "sse")
+
+	client := &Client{
+		hub:       h,
+		log:       h.log.With(logging.ClientAddr, r.RemoteAddr),
+		send:      make(chan interface{}, 256),
+		channels:  channels,
+		dump:      make(chan interface{}, dumpBuffer),
+		dumping:   true,
+		options:   options,
+		encoding:  JSON,
+		grant:     grant,
+		connected: time.Now(),
+	}
+	if seq, ok := h.parseEventID(r.Header.Get("Last-Event-ID")); ok {
+		client.resumeFrom = seq
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	h.register <- client
+	defer func() {
+		// Keep the hub from blocking on us until it closes the channel
+		client.drain()
+		go func() {
+			for range client.send {
+			}
+		}()
+		h.unregister <- client
+	}()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return err
+			}
+			flusher.Flush()
+		case message, ok := <-client.incoming():
+			if !ok {
+				if client.closed() {
+					return nil
+				}
+				continue
+			}
+			err := client.writeEvent(w, message)
+			if err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a message from the send channel as an event
+func (c *Client) writeEvent(w http.ResponseWriter, message interface{}) error {
+	switch m := message.(type) {
+	case syncPoint:
+		// Sets the last event ID without dispatching an event
+		_, err := fmt.Fprintf(w, "id: %s\n\n", c.hub.eventID(uint64(m)))
+		return err
+	case sequenced:
+		entry := c.prepared(m)
+		if entry.err != nil {
+			return entry.err
+		}
+		c.sent(m, len(entry.data))
+		_, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", c.hub.eventID(m.seq), entry.data)
+		return err
+	default:
+		// Dump messages have no place in the sequence
+		data, err := c.encoding.encode(c.unwrap(m))
+		if err != nil {
+			return err
+		}
+		c.sent(m, len(data))
+		_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		return err
+	}
+}
+
+// eventID of a broadcast, <epoch>-<sequence>
+func (h *Hub) eventID(seq uint64) string {
+	return h.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID reads the sequence of an event ID of this hub's epoch
+func (h *Hub) parseEventID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}